@@ -0,0 +1,70 @@
+// Command chapman works with javascript source files.
+//
+//	chapman highlight [-html] [file ...]
+//
+// highlight prints the files, or standard input when none are given, with
+// syntax highlighting as ANSI terminal colours or as HTML markup.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gernest/chapman/lexer"
+)
+
+const usage = `usage: chapman <command> [arguments]
+
+commands:
+	highlight [-html] [-prefix p] [file ...]
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "highlight":
+		err = highlight(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "chapman:", err)
+		os.Exit(1)
+	}
+}
+
+func highlight(args []string) error {
+	fs := flag.NewFlagSet("highlight", flag.ExitOnError)
+	asHTML := fs.Bool("html", false, "write HTML <span class> markup instead of ANSI colours")
+	prefix := fs.String("prefix", "chapman-", "class name prefix for -html")
+	fs.Parse(args)
+
+	var f lexer.Formatter = lexer.ANSIFormatter{}
+	if *asHTML {
+		f = lexer.HTMLFormatter{Prefix: *prefix}
+	}
+	if fs.NArg() == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		return lexer.Highlight(os.Stdout, src, f)
+	}
+	for _, name := range fs.Args() {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := lexer.Highlight(os.Stdout, src, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
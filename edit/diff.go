@@ -0,0 +1,283 @@
+package edit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	a, b int // line index in a and b
+}
+
+func splitLines(src []byte) []string {
+	var lines []string
+	for len(src) > 0 {
+		i := bytes.IndexByte(src, '\n')
+		if i == -1 {
+			lines = append(lines, string(src))
+			break
+		}
+		lines = append(lines, string(src[:i+1]))
+		src = src[i+1:]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script turning a into b using the
+// linear space variant of Myers' O(ND) algorithm, which splits the inputs at
+// the middle of an optimal path and recurses on both halves.
+//
+// http://www.xmailserver.org/diff2.pdf
+func diffLines(a, b []string) []op {
+	var ops []op
+	diffRange(a, b, 0, len(a), 0, len(b), &ops)
+	return groupChanges(ops)
+}
+
+// diffRange appends the edit script turning a[a0:a1] into b[b0:b1] to ops.
+func diffRange(a, b []string, a0, a1, b0, b1 int, ops *[]op) {
+	for a0 < a1 && b0 < b1 && a[a0] == b[b0] {
+		*ops = append(*ops, op{opEqual, a0, b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1 && b0 < b1 && a[a1-1] == b[b1-1] {
+		a1--
+		b1--
+		suffix++
+	}
+	x, y, ok := -1, -1, false
+	if a0 < a1 && b0 < b1 {
+		x, y, ok = middle(a[a0:a1], b[b0:b1])
+	}
+	if ok {
+		diffRange(a, b, a0, a0+x, b0, b0+y, ops)
+		diffRange(a, b, a0+x, a1, b0+y, b1, ops)
+	} else {
+		for i := a0; i < a1; i++ {
+			*ops = append(*ops, op{opDelete, i, b0})
+		}
+		for j := b0; j < b1; j++ {
+			*ops = append(*ops, op{opInsert, a1, j})
+		}
+	}
+	for i := 0; i < suffix; i++ {
+		*ops = append(*ops, op{opEqual, a1 + i, b1 + i})
+	}
+}
+
+// middle finds a point (x, y) on an optimal path through the edit graph of
+// a and b by searching forward from the start and backward from the end at
+// the same time until the two searches meet. It reports false when a and b
+// have nothing in common. Only the furthest point reached on every diagonal
+// is kept, so it takes space linear in the input.
+func middle(a, b []string) (x, y int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vf := make([]int, 2*maxD+2)
+	vb := make([]int, 2*maxD+2)
+	for i := range vf {
+		vf[i] = -1
+		vb[i] = -1
+	}
+	vf[offset+1] = 0
+	vb[offset+1] = 0
+	delta := n - m
+	// with an odd delta the forward search is the one to meet the backward
+	front := delta%2 != 0
+	// diagonals to skip at either end once they leave the edit graph
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || k != d && vf[i-1] < vf[i+1] {
+				x = vf[i+1]
+			} else {
+				x = vf[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[i] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case front:
+				j := offset + delta - k
+				if j >= 0 && j < len(vb) && vb[j] != -1 && x >= n-vb[j] {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || k != d && vb[i-1] < vb[i+1] {
+				x = vb[i+1]
+			} else {
+				x = vb[i-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vb[i] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !front:
+				j := offset + delta - k
+				if j >= 0 && j < len(vf) && vf[j] != -1 && vf[j] >= n-x {
+					return vf[j], vf[j] - (delta - k), true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// groupChanges moves the deletions of every run of changes before its
+// insertions, the order diff output lists them in.
+func groupChanges(ops []op) []op {
+	out := make([]op, 0, len(ops))
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			out = append(out, ops[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(ops) && ops[j].kind != opEqual {
+			j++
+		}
+		x, y := ops[i].a, ops[i].b
+		dels := 0
+		for _, o := range ops[i:j] {
+			if o.kind == opDelete {
+				dels++
+			}
+		}
+		for k := 0; k < dels; k++ {
+			out = append(out, op{opDelete, x + k, y})
+		}
+		for k := 0; k < j-i-dels; k++ {
+			out = append(out, op{opInsert, x + dels, y + k})
+		}
+		i = j
+	}
+	return out
+}
+
+// Unified returns a unified diff between old and new, labelled with name. It
+// returns an empty string when they are equal.
+func Unified(name string, old, new []byte) string {
+	a, b := splitLines(old), splitLines(new)
+	ops := diffLines(a, b)
+	var out strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+		// extend the hunk while changes are closer than twice the context lines
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end += contextLines
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = run
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+		}
+		writeHunk(&out, a, b, ops[start:end])
+		i = end
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, a, b []string, ops []op) {
+	var aLen, bLen int
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			aLen++
+			bLen++
+		case opDelete:
+			aLen++
+		case opInsert:
+			bLen++
+		}
+	}
+	aStart, bStart := ops[0].a+1, ops[0].b+1
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			writeLine(out, ' ', a[o.a])
+		case opDelete:
+			writeLine(out, '-', a[o.a])
+		case opInsert:
+			writeLine(out, '+', b[o.b])
+		}
+	}
+}
+
+func hunkRange(start, n int) string {
+	if n == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+func writeLine(out *strings.Builder, prefix byte, line string) {
+	out.WriteByte(prefix)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,129 @@
+package edit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	sample := []struct {
+		old, new, diff string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{
+			"a\nb\nc\n", "a\nx\nc\n",
+			`--- a/f.js
++++ b/f.js
+@@ -1,3 +1,3 @@
+ a
+-b
++x
+ c
+`,
+		},
+		{
+			"", "a\n",
+			`--- a/f.js
++++ b/f.js
+@@ -0,0 +1 @@
++a
+`,
+		},
+		{
+			"a\nb", "a\nc",
+			`--- a/f.js
++++ b/f.js
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			`--- a/f.js
++++ b/f.js
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -9,4 +10,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+	}
+	for _, v := range sample {
+		d := Unified("f.js", []byte(v.old), []byte(v.new))
+		if d != v.diff {
+			t.Errorf("%q -> %q: expected\n%s\ngot\n%s", v.old, v.new, v.diff, d)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+	ops := diffLines(a, b)
+	var edits int
+	var x, y []string
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			x = append(x, a[o.a])
+			y = append(y, b[o.b])
+		case opDelete:
+			edits++
+			x = append(x, a[o.a])
+		case opInsert:
+			edits++
+			y = append(y, b[o.b])
+		}
+	}
+	if edits != 5 {
+		t.Errorf("expected 5 edits got %d", edits)
+	}
+	if strings.Join(x, " ") != strings.Join(a, " ") || strings.Join(y, " ") != strings.Join(b, " ") {
+		t.Errorf("edit script does not rebuild the inputs: %v %v", x, y)
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	const n = 8000
+	a := make([]string, n)
+	b := make([]string, n)
+	c := make([]string, n)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d\n", i)
+		b[i] = fmt.Sprintf("b%d\n", i)
+		c[i] = a[i]
+		if i%100 == 0 {
+			c[i] = b[i]
+		}
+	}
+	sample := []struct {
+		a, b  []string
+		edits int
+	}{
+		{a, b, 2 * n},
+		{a, c, 2 * n / 100},
+	}
+	for _, v := range sample {
+		edits := 0
+		for _, o := range diffLines(v.a, v.b) {
+			if o.kind != opEqual {
+				edits++
+			}
+		}
+		if edits != v.edits {
+			t.Errorf("expected %d edits got %d", v.edits, edits)
+		}
+	}
+}
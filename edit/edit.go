@@ -0,0 +1,63 @@
+// Package edit applies text edits expressed as byte offsets into a source.
+package edit
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TextEdit replaces the bytes in [Start, End) with NewText. Start == End is an
+// insertion and an empty NewText is a deletion.
+type TextEdit struct {
+	Start   int
+	End     int
+	NewText string
+}
+
+func (e TextEdit) String() string {
+	return fmt.Sprintf("[%d, %d) => %q", e.Start, e.End, e.NewText)
+}
+
+// Apply applies edits to src in offset order. At the same offset insertions
+// come before replacements, otherwise edits keep the order they have in
+// edits. Exact duplicates are merged, and an edit starting inside the range
+// replaced by an edit accepted before it is skipped and returned in
+// rejected.
+func Apply(src []byte, edits []TextEdit) (out []byte, rejected []TextEdit, err error) {
+	for _, e := range edits {
+		if e.Start < 0 || e.End < e.Start || e.End > len(src) {
+			return nil, nil, fmt.Errorf("edit: %v out of range for %d bytes", e, len(src))
+		}
+	}
+	sorted := append([]TextEdit{}, edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		// an insertion goes before the text replaced at its offset
+		return a.Start == a.End && b.Start != b.End
+	})
+	var accepted []TextEdit
+	for _, e := range sorted {
+		if n := len(accepted); n > 0 {
+			last := accepted[n-1]
+			if last == e {
+				continue
+			}
+			if e.Start < last.End {
+				rejected = append(rejected, e)
+				continue
+			}
+		}
+		accepted = append(accepted, e)
+	}
+	offset := 0
+	for _, e := range accepted {
+		out = append(out, src[offset:e.Start]...)
+		out = append(out, e.NewText...)
+		offset = e.End
+	}
+	out = append(out, src[offset:]...)
+	return out, rejected, nil
+}
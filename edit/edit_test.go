@@ -0,0 +1,66 @@
+package edit
+
+import (
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	src := []byte("var a = 1;\nvar b = a == 2;\n")
+	edits := []TextEdit{
+		{Start: 21, End: 23, NewText: "==="},
+		{Start: 0, End: 3, NewText: "let"},
+		{Start: 11, End: 14, NewText: "const"},
+		{Start: 0, End: 3, NewText: "let"},
+		{Start: 11, End: 18, NewText: "x"},
+		{Start: 25, End: 25, NewText: " /* two */"},
+	}
+	out, rejected, err := Apply(src, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := "let a = 1;\nconst b = a === 2 /* two */;\n"
+	if string(out) != e {
+		t.Errorf("expected %q got %q", e, out)
+	}
+	if len(rejected) != 1 || rejected[0] != edits[4] {
+		t.Errorf("expected %v to be rejected got %v", edits[4], rejected)
+	}
+
+	inserts := []TextEdit{
+		{Start: 1, End: 1, NewText: "b"},
+		{Start: 1, End: 1, NewText: "c"},
+	}
+	out, _, err = Apply([]byte("ad"), inserts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "abcd" {
+		t.Errorf("expected abcd got %s", out)
+	}
+
+	// the order of an insertion and a replacement at the same offset does
+	// not matter
+	for _, v := range [][]TextEdit{
+		{{Start: 0, End: 3, NewText: "let"}, {Start: 0, End: 0, NewText: "// x\n"}},
+		{{Start: 0, End: 0, NewText: "// x\n"}, {Start: 0, End: 3, NewText: "let"}},
+	} {
+		out, rejected, err := Apply([]byte("var a;"), v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != "// x\nlet a;" || len(rejected) != 0 {
+			t.Errorf("%v: expected %q got %q, rejected %v", v, "// x\nlet a;", out, rejected)
+		}
+	}
+
+	bad := []TextEdit{
+		{Start: -1, End: 0},
+		{Start: 2, End: 1},
+		{Start: 0, End: 3},
+	}
+	for _, v := range bad {
+		if _, _, err := Apply([]byte("ab"), []TextEdit{v}); err == nil {
+			t.Errorf("expected an error for %v", v)
+		}
+	}
+}
@@ -11,6 +11,10 @@ func (boolLexer) Name() string {
 	return "boolean"
 }
 
+func (boolLexer) canStart(ch rune) bool {
+	return ch == 't' || ch == 'f'
+}
+
 func (boolLexer) Accept(s scanner) bool {
 	n, _, err := s.Peek()
 	if err != nil {
@@ -58,18 +62,16 @@ func (b boolLexer) Lex(s scanner, ctx *context) (*token, error) {
 		if nx == 'f' {
 			limit = 4
 		}
-		var b bytes.Buffer
-		b.WriteRune(nx)
 		for i := 0; i < limit; i++ {
 			nx, w, err = s.Next()
 			if err != nil {
 				return nil, err
 			}
 			end.Column += w
-			b.WriteRune(nx)
 		}
-		tk := &token{Text: b.String(), Start: start, End: end}
-		switch b.String() {
+		tk := ctx.newToken(start)
+		tk.Text, tk.End = s.Text(), end
+		switch tk.Text {
 		case "true":
 			tk.Kind = TRUE
 			return tk, nil
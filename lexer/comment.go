@@ -1,7 +1,6 @@
 package lexer
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 )
@@ -12,6 +11,10 @@ func (singleLineCommentLexer) Name() string {
 	return "singleLineComment"
 }
 
+func (singleLineCommentLexer) canStart(ch rune) bool {
+	return ch == '/'
+}
+
 func (singleLineCommentLexer) Accept(s scanner) bool {
 	n, _, err := s.PeekAt(1)
 	if err != nil {
@@ -46,28 +49,26 @@ func (c singleLineCommentLexer) Lex(s scanner, ctx *context) (*token, error) {
 		}
 		end.Column += w
 		if nx == '/' {
-			var b bytes.Buffer
-			tk := &token{Kind: SingleLineComment}
-			b.WriteString("//")
+			tk := ctx.newToken(start)
+			tk.Kind = SingleLineComment
 			for {
 				x, w, err := s.Next()
 				if err != nil {
 					if err == io.EOF {
-						tk.Text = b.String()
-						tk.Start = start
+						tk.Text = s.Text()
 						tk.End = end
 						return tk, nil
 					}
 					return nil, err
 				}
 				if isLineTerminator(x) {
-					tk.Text = b.String()
-					tk.Start = start
+					// the terminator is not part of the comment
+					s.Rewind()
+					tk.Text = s.Text()
 					tk.End = end
 					return tk, nil
 				}
 				end.Column += w
-				b.WriteRune(x)
 			}
 		}
 	}
@@ -80,6 +81,10 @@ func (multiLineCommentLexer) Name() string {
 	return "multiLineComment"
 }
 
+func (multiLineCommentLexer) canStart(ch rune) bool {
+	return ch == '/'
+}
+
 func (multiLineCommentLexer) Accept(s scanner) bool {
 	n, _, err := s.PeekAt(1)
 	if err != nil {
@@ -114,9 +119,8 @@ func (m multiLineCommentLexer) Lex(s scanner, ctx *context) (*token, error) {
 		}
 		end.Column += w
 		if nx == '*' {
-			var b bytes.Buffer
-			tk := &token{Kind: MultiLineComment, Start: start}
-			b.WriteString("/*")
+			tk := ctx.newToken(start)
+			tk.Kind = MultiLineComment
 			for {
 				x, w, err := s.Next()
 				if err != nil {
@@ -125,13 +129,11 @@ func (m multiLineCommentLexer) Lex(s scanner, ctx *context) (*token, error) {
 				if isLineTerminator(x) {
 					end.Line++
 					end.Column = 0
-					b.WriteRune(x)
 					continue
 				}
 				end.Column += w
-				b.WriteRune(x)
 				if x == '*' {
-					nxt, size, err := s.Next()
+					nxt, size, err := s.Peek()
 					if err != nil {
 						return nil, err
 					}
@@ -139,10 +141,10 @@ func (m multiLineCommentLexer) Lex(s scanner, ctx *context) (*token, error) {
 
 						// we already know the size from peek,so we call next to
 						// advance the cursor
+						s.Next()
 						end.Column += size
-						b.WriteRune(nxt)
 
-						tk.Text = b.String()
+						tk.Text = s.Text()
 						tk.End = end
 						return tk, nil
 					}
@@ -1 +1,61 @@
 package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiLineCommentLexer(t *testing.T) {
+	var l multiLineCommentLexer
+	sample := []string{
+		"/**/",
+		"/***/",
+		"/* a * b */",
+		"/** doc */",
+		"/*\n * a\n * b\n */",
+		"/* 2 ** 3 **/",
+	}
+	for _, v := range sample {
+		s := newBufioScanner(strings.NewReader(v + "x"))
+		if !l.Accept(s) {
+			t.Error("expected to accept", v)
+		}
+		tk, err := l.Lex(s, &context{})
+		if err != nil {
+			t.Fatalf("%q: %v", v, err)
+		}
+		if tk.Text != v {
+			t.Errorf("expected %q got %q", v, tk.Text)
+		}
+	}
+	s := newBufioScanner(strings.NewReader("/* a * b"))
+	if _, err := l.Lex(s, &context{}); err == nil {
+		t.Error("expected an error for an unterminated comment")
+	}
+}
+
+func TestSingleLineCommentLexer(t *testing.T) {
+	tokens := lexTexts(t, "a // b\r\nc")
+	e := []string{"a", " ", "// b", "\r\n", "c"}
+	if strings.Join(tokens, "|") != strings.Join(e, "|") {
+		t.Errorf("expected %q got %q", e, tokens)
+	}
+}
+
+func BenchmarkSingleLineCommentLexer(b *testing.B) {
+	benchmarkLexer(b, singleLineCommentLexer{}, []string{
+		"//",
+		"// a short comment",
+		"// a longer comment, explaining at length what the code below does",
+		"//日本語のコメント",
+	})
+}
+
+func BenchmarkMultiLineCommentLexer(b *testing.B) {
+	benchmarkLexer(b, multiLineCommentLexer{}, []string{
+		"/**/",
+		"/* a short comment */",
+		"/**\n * A doc comment\n * spanning several lines.\n */",
+		"/* 日本語のコメント */",
+	})
+}
@@ -0,0 +1,213 @@
+package lexer
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+// Category is the class a token is highlighted as.
+type Category uint
+
+// highlight categories
+const (
+	CategoryPlain Category = iota
+	CategoryComment
+	CategoryKeyword
+	CategoryIdentifier
+	CategoryString
+	CategoryNumber
+	CategoryLiteral // null, true and false
+	CategoryPunctuation
+	CategoryFunctionName
+	CategoryParameter
+	CategoryProperty
+)
+
+var categoryMap = map[Category]string{
+	CategoryPlain:        "plain",
+	CategoryComment:      "comment",
+	CategoryKeyword:      "keyword",
+	CategoryIdentifier:   "identifier",
+	CategoryString:       "string",
+	CategoryNumber:       "number",
+	CategoryLiteral:      "literal",
+	CategoryPunctuation:  "punctuation",
+	CategoryFunctionName: "function",
+	CategoryParameter:    "parameter",
+	CategoryProperty:     "property",
+}
+
+func (c Category) String() string {
+	return categoryMap[c]
+}
+
+var keywordSet = make(map[string]bool)
+
+func init() {
+	for _, v := range keywords {
+		keywordSet[v] = true
+	}
+}
+
+func isTrivia(k kind) bool {
+	switch k {
+	case LF, CR, LS, PS, TAB, VT, FF, SP, NBSP, ZWNBSP, USP,
+		SingleLineComment, MultiLineComment:
+		return true
+	default:
+		return false
+	}
+}
+
+// classify returns the category of every token in tks.
+//
+// There is no parser yet, so function names, parameters and properties are
+// recognised from the surrounding tokens: the name after the function
+// keyword, the plain identifiers in its parameter list and the name after a
+// period.
+func classify(tks []*token) []Category {
+	c := make([]Category, len(tks))
+
+	// index of the previous significant token
+	prev := -1
+	// parenthesis depth inside a function parameter list, 0 outside of one
+	params := 0
+	inFunction := false
+	for i, tk := range tks {
+		if isTrivia(tk.Kind) {
+			if tk.Kind == SingleLineComment || tk.Kind == MultiLineComment {
+				c[i] = CategoryComment
+			}
+			continue
+		}
+		switch tk.Kind {
+		case STRING:
+			c[i] = CategoryString
+		case INT, FLOAT, HEX, OCTAL, BINARY:
+			c[i] = CategoryNumber
+		case NULL, TRUE, FALSE:
+			c[i] = CategoryLiteral
+		case IdentifierName:
+			switch {
+			case prev != -1 && tks[prev].Kind == PERIOD:
+				c[i] = CategoryProperty
+			case tk.Text == "null" || tk.Text == "true" || tk.Text == "false":
+				c[i] = CategoryLiteral
+			case keywordSet[tk.Text]:
+				c[i] = CategoryKeyword
+			case inFunction && params == 0:
+				c[i] = CategoryFunctionName
+			case params == 1 && prev != -1 &&
+				(tks[prev].Kind == LPAREN || tks[prev].Kind == COMMA ||
+					tks[prev].Kind == ELLIPSIS):
+				c[i] = CategoryParameter
+			default:
+				c[i] = CategoryIdentifier
+			}
+		default:
+			c[i] = CategoryPunctuation
+		}
+
+		switch {
+		case c[i] == CategoryKeyword && tk.Text == "function":
+			inFunction = true
+		case inFunction && tk.Kind == LPAREN:
+			params++
+			if params == 1 {
+				inFunction = false
+			}
+		case params > 0 && tk.Kind == LPAREN:
+			params++
+		case params > 0 && tk.Kind == RPAREN:
+			params--
+		case inFunction && c[i] != CategoryFunctionName && tk.Kind != MUL:
+			// not a function declaration or expression after all
+			inFunction = false
+		}
+		prev = i
+	}
+	return c
+}
+
+// Formatter writes highlighted text.
+type Formatter interface {
+	Format(w io.Writer, c Category, text string) error
+}
+
+// make sure all formatters implement the Formatter interface
+var (
+	_ Formatter = ANSIFormatter{}
+	_ Formatter = HTMLFormatter{}
+)
+
+var ansiColors = map[Category]string{
+	CategoryComment:      "\x1b[90m",
+	CategoryKeyword:      "\x1b[35m",
+	CategoryString:       "\x1b[32m",
+	CategoryNumber:       "\x1b[33m",
+	CategoryLiteral:      "\x1b[33m",
+	CategoryFunctionName: "\x1b[34m",
+	CategoryParameter:    "\x1b[36m",
+	CategoryProperty:     "\x1b[36m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// ANSIFormatter colours text with ANSI escape sequences for terminals.
+type ANSIFormatter struct{}
+
+// Format implements Formatter.
+func (ANSIFormatter) Format(w io.Writer, c Category, text string) error {
+	color, ok := ansiColors[c]
+	if !ok {
+		_, err := io.WriteString(w, text)
+		return err
+	}
+	_, err := io.WriteString(w, color+text+ansiReset)
+	return err
+}
+
+// HTMLFormatter wraps text in <span> elements whose class names the
+// category, prefixed with Prefix.
+type HTMLFormatter struct {
+	Prefix string
+}
+
+// Format implements Formatter.
+func (h HTMLFormatter) Format(w io.Writer, c Category, text string) error {
+	if c == CategoryPlain {
+		_, err := io.WriteString(w, html.EscapeString(text))
+		return err
+	}
+	_, err := fmt.Fprintf(w, `<span class="%s%s">%s</span>`,
+		h.Prefix, c, html.EscapeString(text))
+	return err
+}
+
+// Highlight writes src to w with every token formatted by f. Text following
+// the first position the lexer cannot handle is written as CategoryPlain.
+func Highlight(w io.Writer, src []byte, f Formatter) error {
+	tks, _ := lexBytes(src, defaultLexMe()...)
+	offset, err := format(w, tks, f)
+	if err != nil {
+		return err
+	}
+	if offset < len(src) {
+		return f.Format(w, CategoryPlain, string(src[offset:]))
+	}
+	return nil
+}
+
+// format writes tks to w formatted by f, and returns the length of the source
+// they cover.
+func format(w io.Writer, tks []*token, f Formatter) (int, error) {
+	offset := 0
+	for i, c := range classify(tks) {
+		if err := f.Format(w, c, tks[i].Text); err != nil {
+			return offset, err
+		}
+		offset += len(tks[i].Text)
+	}
+	return offset, nil
+}
@@ -0,0 +1,171 @@
+package lexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	src := `function add(a, ...b) { return a.length + 1 } // "done"`
+	tks, err := lex(strings.NewReader(src), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []struct {
+		text     string
+		category Category
+	}{
+		{"function", CategoryKeyword},
+		{" ", CategoryPlain},
+		{"add", CategoryFunctionName},
+		{"(", CategoryPunctuation},
+		{"a", CategoryParameter},
+		{",", CategoryPunctuation},
+		{" ", CategoryPlain},
+		{"...", CategoryPunctuation},
+		{"b", CategoryParameter},
+		{")", CategoryPunctuation},
+		{" ", CategoryPlain},
+		{"{", CategoryPunctuation},
+		{" ", CategoryPlain},
+		{"return", CategoryKeyword},
+		{" ", CategoryPlain},
+		{"a", CategoryIdentifier},
+		{".", CategoryPunctuation},
+		{"length", CategoryProperty},
+		{" ", CategoryPlain},
+		{"+", CategoryPunctuation},
+		{" ", CategoryPlain},
+		{"1", CategoryNumber},
+		{" ", CategoryPlain},
+		{"}", CategoryPunctuation},
+		{" ", CategoryPlain},
+		{`// "done"`, CategoryComment},
+	}
+	c := classify(tks)
+	if len(c) != len(e) {
+		t.Fatalf("expected %d tokens got %d", len(e), len(c))
+	}
+	for i, v := range e {
+		if tks[i].Text != v.text || c[i] != v.category {
+			t.Errorf("%d: expected %q %s got %q %s", i, v.text, v.category, tks[i].Text, c[i])
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	src := "var x = \"<b>\" // hi\n"
+	var b bytes.Buffer
+	err := Highlight(&b, []byte(src), HTMLFormatter{Prefix: "js-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `<span class="js-keyword">var</span> <span class="js-identifier">x</span> ` +
+		`<span class="js-punctuation">=</span> <span class="js-string">&#34;&lt;b&gt;&#34;</span> ` +
+		`<span class="js-comment">// hi</span>` + "\n"
+	if b.String() != e {
+		t.Errorf("expected %s got %s", e, b.String())
+	}
+
+	b.Reset()
+	err = Highlight(&b, []byte("null;"), ANSIFormatter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = "\x1b[33mnull\x1b[0m;"
+	if b.String() != e {
+		t.Errorf("expected %q got %q", e, b.String())
+	}
+}
+
+type plainFormatter struct{}
+
+func (plainFormatter) Format(w io.Writer, c Category, text string) error {
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// unsupported lists fixtures using syntax the lexer does not handle yet, see
+// ROADMAP.md.
+var unsupported = map[string]string{
+	"fixture/experimental/bigint":            "bigint literals",
+	"fixture/experimental/numeric-separator": "numeric separators",
+	"fixture/jsx":                            "jsx",
+	"fixture/core/uncategorised/106":         "regular expression literals",
+	"fixture/core/uncategorised/107":         "regular expression literals",
+	"fixture/core/uncategorised/91":          "legacy octal escapes",
+	"fixture/core/uncategorised/92":          "legacy octal escapes",
+	"fixture/core/uncategorised/93":          "legacy octal escapes",
+	"fixture/core/uncategorised/94":          "legacy octal escapes",
+	"fixture/core/uncategorised/95":          "legacy octal escapes",
+	"fixture/core/uncategorised/96":          "legacy octal escapes",
+	"fixture/core/uncategorised/97":          "legacy octal escapes",
+	"fixture/core/uncategorised/98":          "legacy octal escapes",
+}
+
+func isUnsupported(p string, src []byte) bool {
+	// template literals, private names and hash bangs
+	if bytes.ContainsAny(src, "`#") {
+		return true
+	}
+	for dir := filepath.Dir(p); dir != "."; dir = filepath.Dir(dir) {
+		if _, ok := unsupported[dir]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// throws reports whether the fixture in dir is expected to fail. Most
+// fixtures give throws as the expected message rather than true, which opts
+// cannot read.
+func throws(dir string) bool {
+	b, err := ioutil.ReadFile(filepath.Join(dir, "options.json"))
+	if err != nil {
+		return false
+	}
+	var o struct {
+		Throws interface{} `json:"throws"`
+	}
+	return json.Unmarshal(b, &o) == nil && o.Throws != nil && o.Throws != false
+}
+
+func TestHighlightPreservesSource(t *testing.T) {
+	for _, p := range fixtures(t) {
+		if throws(filepath.Dir(p)) {
+			continue
+		}
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isUnsupported(p, src) {
+			continue
+		}
+		tks, err := lexBytes(src, defaultLexMe()...)
+		if err != nil {
+			t.Errorf("%s: %v", p, err)
+			continue
+		}
+		var b bytes.Buffer
+		for _, tk := range tks {
+			b.WriteString(tk.Text)
+		}
+		if b.String() != string(src) {
+			t.Errorf("%s: tokens do not cover the source, expected %q got %q", p, src, b.String())
+			continue
+		}
+		b.Reset()
+		if err := Highlight(&b, src, plainFormatter{}); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != string(src) {
+			t.Errorf("%s: expected %q got %q", p, src, b.String())
+		}
+	}
+}
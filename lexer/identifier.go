@@ -1,9 +1,9 @@
 package lexer
 
 import (
-	"bytes"
 	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 const reverseSolidus = 0x005C // backslash
@@ -14,6 +14,11 @@ func (identifierNameLexer) Name() string {
 	return "identifierName"
 }
 
+func (identifierNameLexer) canStart(ch rune) bool {
+	return ch >= utf8.RuneSelf || isUnicodeIDStart(ch) ||
+		ch == '$' || ch == '_' || ch == reverseSolidus
+}
+
 func (identifierNameLexer) Accept(s scanner) bool {
 	ch, _, err := s.Peek()
 	if err != nil {
@@ -39,106 +44,99 @@ func (i identifierNameLexer) Lex(s scanner, ctx *context) (*token, error) {
 	if ctx.lastToken != nil {
 		start, end = ctx.lastToken.End, start
 	}
-	var b bytes.Buffer
-	tk := &token{Kind: IdentifierName, Start: start}
-	e, err := i.lexStart(s, ctx, end, &b)
+	tk := ctx.newToken(start)
+	tk.Kind = IdentifierName
+	end, err := i.lexStart(s, ctx, end)
 	if err != nil {
 		return nil, err
 	}
-	e, err = i.lexPart(s, ctx, *e, &b)
+	end, err = i.lexPart(s, ctx, end)
 	if err != nil {
 		return nil, err
 	}
-	end = *e
 	tk.End = end
-	tk.Text = b.String()
+	tk.Text = s.Text()
 	return tk, nil
 }
 
-func (i identifierNameLexer) lexStart(s scanner, ctx *context, end position, b *bytes.Buffer) (*position, error) {
+func (i identifierNameLexer) lexStart(s scanner, ctx *context, end position) (position, error) {
 	n, w, err := s.Next()
 	if err != nil {
-		return nil, err
+		return end, err
 	}
 	end.Column += w
-	if isUnicodeIDStart(n) || n == '$' || n == '_' {
-		b.WriteRune(n)
-	} else if n == reverseSolidus {
-		b.WriteRune(n)
+	if n == reverseSolidus {
 		nx, w, err := s.Next()
 		if err != nil {
-			return nil, err
+			return end, err
 		}
 		end.Column += w
 		if nx != 'u' {
-			return nil, fmt.Errorf(unexpectedTkn, i.Name(), end)
+			return end, fmt.Errorf(unexpectedTkn, i.Name(), end)
 		}
-		b.WriteRune(nx)
 
 		// wer are lexing a valid UnicodeEscapeSequence
 		nx, w, err = s.Next()
 		if err != nil {
-			return nil, err
+			return end, err
 		}
 		end.Column += w
-		b.WriteRune(nx)
 		if isHexDigit(nx) {
 			// four hex digits, we already have one three to go
 			for k := 0; k < 3; k++ {
 				nx, w, err = s.Next()
 				if err != nil {
-					return nil, err
+					return end, err
 				}
 				end.Column += w
-				b.WriteRune(nx)
 				if !isHexDigit(nx) {
-					return nil, fmt.Errorf(unexpectedTkn, i.Name(), end)
+					return end, fmt.Errorf(unexpectedTkn, i.Name(), end)
 				}
 			}
-			return &end, nil
+			return end, nil
 		}
 		if nx == '{' {
 			for {
 				nx, w, err = s.Next()
 				if err != nil {
-					return nil, err
+					return end, err
 				}
 				end.Column += w
-				b.WriteRune(nx)
 				if !isHexDigit(nx) {
 					if nx == '}' {
-						return &end, nil
+						return end, nil
 					}
-					return nil, fmt.Errorf(unexpectedTkn, i.Name(), end)
+					return end, fmt.Errorf(unexpectedTkn, i.Name(), end)
 				}
 			}
 		}
 	}
-	return &end, nil
+	return end, nil
 }
-func (i identifierNameLexer) lexPart(s scanner, ctx *context, end position, b *bytes.Buffer) (*position, error) {
+func (i identifierNameLexer) lexPart(s scanner, ctx *context, end position) (position, error) {
 	for {
-		if i.Accept(s) {
-			e, err := i.lexStart(s, ctx, end, b)
-			if err != nil {
-				return nil, err
+		nx, w, err := s.Peek()
+		if err != nil {
+			if err == io.EOF {
+				return end, nil
 			}
-			end.Column = e.Column
-		} else {
-			nx, w, err := s.Peek()
-			if err != nil {
-				if err == io.EOF {
-					return &end, nil
-				}
-				return nil, err
+			return end, err
+		}
+		switch {
+		case nx == reverseSolidus:
+			if !escapeSequence(nx, s) {
+				return end, nil
 			}
-			if isUnicodeIDContinue(nx) || nx == 0x200C || nx == 0x200D {
-				s.Next()
-				end.Column += w
-				b.WriteRune(nx)
-				continue
+			e, err := i.lexStart(s, ctx, end)
+			if err != nil {
+				return end, err
 			}
-			return &end, nil
+			end.Column = e.Column
+		case isUnicodeIDContinue(nx) || nx == '$' || nx == 0x200C || nx == 0x200D:
+			s.Next()
+			end.Column += w
+		default:
+			return end, nil
 		}
 	}
 }
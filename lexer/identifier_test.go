@@ -39,3 +39,43 @@ func TestIdentifierNameLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestKeywords(t *testing.T) {
+	// these were once merged into single entries of keywords
+	for _, v := range []string{"in", "instanceof", "super", "switch", "var", "void"} {
+		if !keywordSet[v] {
+			t.Errorf("expected %s to be a keyword", v)
+		}
+	}
+	for _, v := range []string{"ininstanceof", "superswitch", "varvoid"} {
+		if keywordSet[v] {
+			t.Errorf("expected %s not to be a keyword", v)
+		}
+	}
+
+	src := "var v = void 0; a instanceof B; k in o; switch (x) {} super.y"
+	tks, err := lexBytes([]byte(src), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for i, c := range classify(tks) {
+		if c == CategoryKeyword {
+			n++
+		}
+		if keywordSet[tks[i].Text] && c != CategoryKeyword {
+			t.Errorf("%s: expected %s got %s", tks[i].Text, CategoryKeyword, c)
+		}
+	}
+	if n != 6 {
+		t.Errorf("expected 6 keywords got %d", n)
+	}
+}
+
+func BenchmarkIdentifierNameLexer(b *testing.B) {
+	samples := append([]string{
+		"x", "camelCaseName", "$jquery", "_private", "SCREAMING_CASE_1",
+		"ünïcödé", "\\u0061bc",
+	}, keywords...)
+	benchmarkLexer(b, identifierNameLexer{}, samples)
+}
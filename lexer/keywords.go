@@ -20,15 +20,18 @@ var keywords = []string{
 	"function",
 	"if",
 	"import",
-	"ininstanceof",
+	"in",
+	"instanceof",
 	"new",
 	"return",
-	"superswitch",
+	"super",
+	"switch",
 	"this",
 	"throw",
 	"try",
 	"typeof",
-	"varvoid",
+	"var",
+	"void",
 	"while",
 	"with",
 	"yield",
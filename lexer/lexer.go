@@ -2,7 +2,9 @@ package lexer
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"unicode"
@@ -15,26 +17,17 @@ type kind uint
 
 type token struct {
 	Text  string
-	Kind  kind   `json:"-"`
-	RKind string `json:"Kind"`
+	Kind  kind
 	Start position
 	End   position
 }
 
-func newToken(start position) *token {
-	return &token{Start: start}
-}
-
-func (t *token) AddRune(ch rune) {
-	t.AddString(string(ch))
-}
-func (t *token) AddString(txt string) {
-	t.Text += txt
-	t.End.Column += len(txt)
+// extend moves the end of the token past ch.
+func (t *token) extend(ch rune) {
+	t.End.Column += utf8.RuneLen(ch)
 }
 
 func printToken(tk *token) []byte {
-	tk.RKind = tk.Kind.String()
 	b, err := json.MarshalIndent(tk, "", "\t")
 	if err != nil {
 		fmt.Println(err)
@@ -43,10 +36,6 @@ func printToken(tk *token) []byte {
 }
 
 func printTokens(tks []*token) ([]byte, error) {
-	for i := 0; i < len(tks); i++ {
-		tk := tks[i]
-		tk.RKind = tk.Kind.String()
-	}
 	b, err := json.MarshalIndent(tks, "", "\t")
 	if err != nil {
 		fmt.Println(err)
@@ -60,7 +49,6 @@ func decodeToken(b []byte) (*token, error) {
 	if err != nil {
 		return nil, err
 	}
-	t.Kind = getKind(t.RKind)
 	return t, nil
 }
 
@@ -152,6 +140,7 @@ const (
 	QN    // ?
 	TILDE //~
 	ARROW // =>
+	AT    // @
 
 	Template
 
@@ -240,6 +229,7 @@ var kindMap = map[kind]string{
 	QN:                "QUESTION_MARK",
 	TILDE:             "TILDE",
 	ARROW:             "ARROW",
+	AT:                "AT",
 	NULL:              "NULL",
 	TRUE:              "TRUE",
 	FALSE:             "FALSE",
@@ -268,27 +258,58 @@ func (k kind) MarshalJSON() ([]byte, error) {
 	return json.Marshal(k.String())
 }
 
+func (k *kind) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*k = getKind(s)
+	return nil
+}
+
 func getKind(k string) kind {
 	return reverseKindMap[k]
 }
 
 // scanner is an interface for reading one token at a time from UTF text.
+//
+// Scanners remember the text read since the last call to Mark, lexers take
+// their token text from there instead of building it rune by rune.
 type scanner interface {
 	Next() (rune, int, error)
 	Peek() (rune, int, error)
 	PeekAt(n int) (rune, int, error)
 	Rewind() error
+	Mark()
+	Text() string
 }
 
 type bufioScanner struct {
 	src *bufio.Reader
+
+	// text read since the last mark
+	text bytes.Buffer
 }
 
 func newBufioScanner(r io.Reader) *bufioScanner {
 	return &bufioScanner{src: bufio.NewReader(r)}
 }
 func (b *bufioScanner) Next() (rune, int, error) {
-	return b.src.ReadRune()
+	ch, size, err := b.src.ReadRune()
+	if err != nil {
+		return ch, size, err
+	}
+	if ch == utf8.RuneError && size == 1 {
+		// keep the invalid byte as it is in the source, reading it again
+		// so that it can still be unread
+		b.src.UnreadRune()
+		c, _ := b.src.Peek(1)
+		b.text.WriteByte(c[0])
+		b.src.ReadRune()
+		return ch, size, nil
+	}
+	b.text.WriteRune(ch)
+	return ch, size, nil
 }
 
 func (b *bufioScanner) Peek() (ch rune, size int, err error) {
@@ -297,26 +318,164 @@ func (b *bufioScanner) Peek() (ch rune, size int, err error) {
 
 // reads the nth rune without advancing the reader
 func (b *bufioScanner) PeekAt(n int) (ch rune, size int, err error) {
-	bv, err := b.peekChunck(n)
+	// n runes take at most n*utf8.UTFMax bytes, it is fine to get fewer back
+	bv, err := b.peekChunck(n * utf8.UTFMax)
 	width := 0
 	for i := 0; i < n; i++ {
+		if width >= len(bv) {
+			if err == nil {
+				err = io.EOF
+			}
+			return 0, 0, err
+		}
 		ch, size = utf8.DecodeRune(bv[width:])
 		width += size
 	}
-	return
+	return ch, size, nil
 }
 
 func (b *bufioScanner) Rewind() error {
-	return b.src.UnreadRune()
+	if err := b.src.UnreadRune(); err != nil {
+		return err
+	}
+	_, size := utf8.DecodeLastRune(b.text.Bytes())
+	b.text.Truncate(b.text.Len() - size)
+	return nil
+}
+
+func (b *bufioScanner) Mark() {
+	b.text.Reset()
+}
+
+func (b *bufioScanner) Text() string {
+	return b.text.String()
 }
 
 func (b *bufioScanner) peekChunck(n int) ([]byte, error) {
 	return b.src.Peek(n)
 }
 
+// bytesScanner reads runes straight from an in-memory source.
+//
+// The source is either text, which token texts are slices of and share
+// memory with, or src, which each token text is copied out of.
+type bytesScanner struct {
+	text string
+	src  []byte
+	off  int
+	mark int
+
+	// width of the rune returned by the last call to Next, 0 when there is
+	// nothing to rewind
+	last int
+}
+
+func newBytesScanner(src []byte) *bytesScanner {
+	return &bytesScanner{text: string(src)}
+}
+
+func newStringScanner(text string) *bytesScanner {
+	return &bytesScanner{text: text}
+}
+
+// newBytesScannerAt returns a scanner starting at off in src that copies
+// token texts out of src, for lexing a few tokens without copying the whole
+// source.
+func newBytesScannerAt(src []byte, off int) *bytesScanner {
+	return &bytesScanner{src: src, off: off, mark: off}
+}
+
+// decode returns the rune at off.
+func (b *bytesScanner) decode(off int) (rune, int, error) {
+	if b.src == nil {
+		if off >= len(b.text) {
+			return 0, 0, io.EOF
+		}
+		if ch := b.text[off]; ch < utf8.RuneSelf {
+			return rune(ch), 1, nil
+		}
+		ch, size := utf8.DecodeRuneInString(b.text[off:])
+		return ch, size, nil
+	}
+	if off >= len(b.src) {
+		return 0, 0, io.EOF
+	}
+	if ch := b.src[off]; ch < utf8.RuneSelf {
+		return rune(ch), 1, nil
+	}
+	ch, size := utf8.DecodeRune(b.src[off:])
+	return ch, size, nil
+}
+
+func (b *bytesScanner) Next() (rune, int, error) {
+	ch, size, err := b.decode(b.off)
+	b.off += size
+	b.last = size
+	return ch, size, err
+}
+
+func (b *bytesScanner) Peek() (rune, int, error) {
+	return b.PeekAt(1)
+}
+
+// reads the nth rune without advancing the scanner. Like bufio.Reader,
+// peeking forgets the last rune read, so it can not be rewound.
+func (b *bytesScanner) PeekAt(n int) (ch rune, size int, err error) {
+	b.last = 0
+	off := b.off
+	for i := 0; i < n; i++ {
+		ch, size, err = b.decode(off)
+		if err != nil {
+			return 0, 0, err
+		}
+		off += size
+	}
+	return ch, size, nil
+}
+
+func (b *bytesScanner) Rewind() error {
+	if b.last == 0 {
+		return errors.New("lexer: nothing to rewind")
+	}
+	b.off -= b.last
+	b.last = 0
+	return nil
+}
+
+func (b *bytesScanner) Mark() {
+	b.mark = b.off
+}
+
+func (b *bytesScanner) Text() string {
+	if b.src != nil {
+		return string(b.src[b.mark:b.off])
+	}
+	return b.text[b.mark:b.off]
+}
+
 type context struct {
-	lexers    map[string]lexMe
 	lastToken *token
+
+	// tokens is where newToken allocates from, a chunk at a time, and used
+	// how many of them are taken.
+	tokens []token
+	used   int
+}
+
+// tokenChunk is the number of tokens allocated together by newToken.
+const tokenChunk = 512
+
+// newToken returns a new token starting at start. Tokens are allocated in
+// chunks; lexing produces a great many small tokens, and allocating each on
+// its own dominates the time spent.
+func (c *context) newToken(start position) *token {
+	if c.used == len(c.tokens) {
+		c.tokens, c.used = make([]token, tokenChunk), 0
+	}
+	tk := &c.tokens[c.used]
+	c.used++
+	tk.Start = start
+	return tk
 }
 
 type position struct {
@@ -334,11 +493,58 @@ type lexMe interface {
 	Lex(scanner, *context) (*token, error)
 }
 
+// starter is implemented by lexers that know which runes the tokens they
+// accept can start with. lexEach only asks those lexers whether they accept
+// a token starting with such a rune, instead of asking every lexer in turn.
+type starter interface {
+	// canStart reports whether a token can start with ch. Runes outside
+	// ASCII are asked for together, as utf8.RuneSelf.
+	canStart(ch rune) bool
+}
+
+// dispatch lists, for every ASCII character, the lexers that may accept a
+// token starting with it, in the order of lexmes. The last entry is for all
+// other runes. Lexers that do not implement starter are listed everywhere.
+type dispatch [utf8.RuneSelf + 1][]lexMe
+
+func newDispatch(lexmes []lexMe) *dispatch {
+	d := &dispatch{}
+	all := make([]lexMe, 0, len(d)*len(lexmes))
+	for ch := range d {
+		start := len(all)
+		for _, v := range lexmes {
+			if st, ok := v.(starter); !ok || st.canStart(rune(ch)) {
+				all = append(all, v)
+			}
+		}
+		d[ch] = all[start:len(all):len(all)]
+	}
+	return d
+}
+
+// next returns the lexer that accepts the next token of s, or nil.
+func (d *dispatch) next(s scanner) lexMe {
+	ch, _, err := s.Peek()
+	if err != nil {
+		return nil
+	}
+	if ch >= utf8.RuneSelf || ch < 0 {
+		ch = utf8.RuneSelf
+	}
+	for _, v := range d[ch] {
+		if v.Accept(s) {
+			return v
+		}
+	}
+	return nil
+}
+
 // make sure all lexers implement lexMe interface
 var (
 	_ lexMe = singleLineCommentLexer{}
 	_ lexMe = multiLineCommentLexer{}
 	_ lexMe = lineTerminatorLexer{}
+	_ lexMe = whiteSpaceLexer{}
 	_ lexMe = identifierNameLexer{}
 	_ lexMe = punctuationLexer{}
 	_ lexMe = boolLexer{}
@@ -353,6 +559,7 @@ func defaultLexMe() []lexMe {
 		singleLineCommentLexer{},
 		multiLineCommentLexer{},
 		lineTerminatorLexer{},
+		whiteSpaceLexer{},
 		identifierNameLexer{},
 		punctuationLexer{},
 		boolLexer{},
@@ -363,33 +570,92 @@ func defaultLexMe() []lexMe {
 }
 
 func lex(src io.Reader, lexmes ...lexMe) ([]*token, error) {
-	s := &bufioScanner{bufio.NewReader(src)}
-	ctx := &context{lexers: make(map[string]lexMe)}
-	for _, v := range lexmes {
-		ctx.lexers[v.Name()] = v
-	}
-	nextLexer := func() lexMe {
-		for i := 0; i < len(lexmes); i++ {
-			if lexmes[i].Accept(s) {
-				return lexmes[i]
-			}
-		}
-		return nil
-	}
-	var tokens []*token
+	return lexAll(newBufioScanner(src), 0, lexmes)
+}
+
+// lexBytes is like lex for a source already in memory. Token texts are
+// slices of a single copy of src, so it allocates far less than lex and knows
+// about how many tokens to make room for.
+func lexBytes(src []byte, lexmes ...lexMe) ([]*token, error) {
+	return lexAll(newBytesScanner(src), len(src)/bytesPerToken, lexmes)
+}
+
+// bytesPerToken is about how long tokens are on average, used to guess how
+// many tokens a source has. White space between tokens is lexed as tokens of
+// its own, which keeps it low.
+const bytesPerToken = 2
+
+// lexAll lexes all of s. n is how many tokens are expected, if known.
+func lexAll(s scanner, n int, lexmes []lexMe) ([]*token, error) {
+	tokens := make([]*token, 0, n)
+	err := lexEach(s, nil, lexmes, func(tk *token) bool {
+		tokens = append(tokens, tk)
+		return true
+	})
+	return tokens, err
+}
+
+// lexEach lexes from s calling fn with every token until fn returns false.
+// last is the token preceding the source, if any, and positions continue
+// from its end. It is an error for the source to hold text none of lexmes
+// accepts.
+func lexEach(s scanner, last *token, lexmes []lexMe, fn func(*token) bool) error {
+	ctx := &context{lastToken: last}
+	d := newDispatch(lexmes)
 	for {
-		v := nextLexer()
+		v := d.next(s)
 		if v == nil {
-			break
+			_, _, err := s.Peek()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			// none of the lexers handles what follows
+			var end position
+			if ctx.lastToken != nil {
+				end = ctx.lastToken.End
+			}
+			return fmt.Errorf(unexpectedTkn, "lexer", end)
 		}
+		s.Mark()
 		tk, err := v.Lex(s, ctx)
 		if err != nil {
-			return tokens, err
+			return err
 		}
-		tokens = append(tokens, tk)
+		// Tokens cover the source without gaps, so their positions follow
+		// from the text alone.
+		tk.Start = position{}
+		if ctx.lastToken != nil {
+			tk.Start = ctx.lastToken.End
+		}
+		tk.End = advance(tk.Start, tk.Text)
 		ctx.lastToken = tk
+		if !fn(tk) {
+			break
+		}
+	}
+	return nil
+}
+
+// advance returns the position reached after text starting at p. Lines are
+// counted from 0 and columns in bytes.
+func advance(p position, text string) position {
+	for i := 0; i < len(text); {
+		ch, w := utf8.DecodeRuneInString(text[i:])
+		i += w
+		if isLineTerminator(ch) {
+			if ch == '\r' && i < len(text) && text[i] == '\n' {
+				i++
+			}
+			p.Line++
+			p.Column = 0
+			continue
+		}
+		p.Column += w
 	}
-	return tokens, nil
+	return p
 }
 
 // # Derived Property: ID_Start
@@ -401,6 +667,9 @@ func lex(src io.Reader, lexmes ...lexMe) ([]*token, error) {
 // #    - Pattern_White_Space
 // http://unicode.org/reports/tr44/#Simple_Derived
 func isUnicodeIDStart(ch rune) bool {
+	if ch < utf8.RuneSelf {
+		return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z'
+	}
 	if unicode.In(ch, unicode.Lu, unicode.Ll,
 		unicode.Lt, unicode.Lm, unicode.Lo,
 		unicode.Nl, unicode.Other_ID_Start) {
@@ -410,8 +679,19 @@ func isUnicodeIDStart(ch rune) bool {
 	return false
 }
 
+// # Derived Property: ID_Continue
+// #  Characters that can continue an identifier.
+// #  Generated from:
+// #      ID_Start
+// #    + Mn + Mc + Nd + Pc
+// #    + Other_ID_Continue
+// #    - Pattern_Syntax
+// #    - Pattern_White_Space
 func isUnicodeIDContinue(ch rune) bool {
-	if isUnicodeIDStart(ch) && unicode.In(ch, unicode.Mn, unicode.Mc,
+	if ch < utf8.RuneSelf {
+		return isUnicodeIDStart(ch) || isDecimalDigit(ch) || ch == '_'
+	}
+	if isUnicodeIDStart(ch) || unicode.In(ch, unicode.Mn, unicode.Mc,
 		unicode.Nd, unicode.Pc, unicode.Other_ID_Continue) {
 		return !unicode.In(ch, unicode.Pattern_Syntax,
 			unicode.Pattern_White_Space)
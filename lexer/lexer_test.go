@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 const expectTokenDecode = `{
@@ -114,3 +116,240 @@ func opts(dir string) (*options, error) {
 	}
 	return o, nil
 }
+
+// fixtures returns the path of every actual.js in fixture.
+func fixtures(tb testing.TB) []string {
+	var files []string
+	err := filepath.Walk("fixture", func(p string, i os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Base(p) == "actual.js" {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return files
+}
+
+// lexTexts lexes src with the default lexers and returns the token texts.
+func lexTexts(tb testing.TB, src string) []string {
+	tks, err := lexBytes([]byte(src), defaultLexMe()...)
+	if err != nil {
+		tb.Fatalf("%q: %v", src, err)
+	}
+	texts := make([]string, len(tks))
+	for i, tk := range tks {
+		texts[i] = tk.Text
+	}
+	return texts
+}
+
+// FuzzLex checks that lexing any input ends, and that the tokens follow each
+// other without gaps and rebuild the input, or the part of it lexed before
+// an error. Both scanners must give the same tokens.
+func FuzzLex(f *testing.F) {
+	for _, p := range fixtures(f) {
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(src)
+	}
+	// invalid UTF-8 and prefixed numbers
+	f.Add([]byte("// \xff\n'\x80' 0b101 0o17 0XfF"))
+	f.Fuzz(func(t *testing.T, src []byte) {
+		var tks, fromBytes []*token
+		var err, errBytes error
+		done := make(chan struct{})
+		go func() {
+			tks, err = lex(bytes.NewReader(src), defaultLexMe()...)
+			fromBytes, errBytes = lexBytes(src, defaultLexMe()...)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("lexing %q did not end", src)
+		}
+
+		var b bytes.Buffer
+		var end position
+		for i, tk := range tks {
+			if tk.Text == "" {
+				t.Fatalf("%q: empty token %d at %v", src, i, tk.Start)
+			}
+			if tk.Start != end {
+				t.Fatalf("%q: token %d %q starts at %v, the previous one ends at %v",
+					src, i, tk.Text, tk.Start, end)
+			}
+			if tk.End.Line < tk.Start.Line ||
+				tk.End.Line == tk.Start.Line && tk.End.Column <= tk.Start.Column {
+				t.Fatalf("%q: token %d %q ends at %v before its start %v",
+					src, i, tk.Text, tk.End, tk.Start)
+			}
+			end = tk.End
+			b.WriteString(tk.Text)
+		}
+		if err == nil && b.String() != string(src) {
+			t.Fatalf("%q: tokens make up %q", src, b.String())
+		}
+		if !bytes.HasPrefix(src, b.Bytes()) {
+			t.Fatalf("%q: tokens make up %q, which does not start it", src, b.String())
+		}
+
+		if (err == nil) != (errBytes == nil) || len(fromBytes) != len(tks) {
+			t.Fatalf("%q: lexed %d tokens (%v) from a reader but %d (%v) from bytes",
+				src, len(tks), err, len(fromBytes), errBytes)
+		}
+		for i, tk := range fromBytes {
+			if *tk != *tks[i] {
+				t.Fatalf("%q: token %d is %+v from a reader but %+v from bytes",
+					src, i, tks[i], tk)
+			}
+		}
+	})
+}
+
+var corpus []byte
+
+// fixtureCorpus returns every fixture the lexer handles completely,
+// concatenated into one large source.
+func fixtureCorpus(b *testing.B) []byte {
+	if corpus != nil {
+		return corpus
+	}
+	var buf bytes.Buffer
+	for _, p := range fixtures(b) {
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := lexBytes(src, defaultLexMe()...); err == nil {
+			buf.Write(src)
+			buf.WriteByte('\n')
+		}
+	}
+	corpus = buf.Bytes()
+	return corpus
+}
+
+func BenchmarkLex(b *testing.B) {
+	src := fixtureCorpus(b)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lex(bytes.NewReader(src), defaultLexMe()...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLexBytes(b *testing.B) {
+	src := fixtureCorpus(b)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lexBytes(src, defaultLexMe()...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkLexer measures l alone, lexing samples one after another on
+// separate lines.
+func benchmarkLexer(b *testing.B, l lexMe, samples []string) {
+	src := []byte(strings.Join(samples, "\n"))
+	lexSamples := func() {
+		s := newBytesScanner(src)
+		ctx := &context{}
+		for k := 0; ; k++ {
+			s.Mark()
+			tk, err := l.Lex(s, ctx)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if tk.Text != samples[k] {
+				b.Fatalf("expected %q got %q", samples[k], tk.Text)
+			}
+			// the line feed between samples
+			if _, _, err := s.Next(); err == io.EOF {
+				return
+			}
+		}
+	}
+	lexSamples()
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lexSamples()
+	}
+}
+
+// statements are the templates generateSource builds sources from. Each is
+// formatted with a single number.
+var statements = []string{
+	"var name%[1]d = \"a string value %[1]d\";\n",
+	"let other%[1]d = 'single quoted\\n' + name%[1]d;\n",
+	"// a single line comment number %[1]d\n",
+	"/*\n * a multi line comment number %[1]d\n */\n",
+	"function add%[1]d(a, b, ...rest) {\n\treturn a * b + 0x%[1]x - %[1]d.5e-3;\n}\n",
+	"if (value%[1]d >= 10 && other !== null) {\n\tobject.property%[1]d = true;\n} else {\n\tobject.property%[1]d = false;\n}\n",
+	"for (let i = 0; i < %[1]d; i++) { total += items[i] ** 2 + items[%[1]d]; }\n",
+	"const ünïcödé%[1]d = { key: [1, 2, 3], 'quoted key': \"日本語\" };\n",
+}
+
+// generateSource returns at least n bytes of javascript made of all kinds of
+// tokens, every one of which the lexer handles.
+func generateSource(n int) []byte {
+	var b bytes.Buffer
+	for i := 0; b.Len() < n; i++ {
+		fmt.Fprintf(&b, statements[i%len(statements)], i)
+	}
+	return b.Bytes()
+}
+
+func TestGenerateSource(t *testing.T) {
+	src := generateSource(64 << 10)
+	tks, err := lexBytes(src, defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, tk := range tks {
+		b.WriteString(tk.Text)
+	}
+	if b.String() != string(src) {
+		t.Errorf("lexed %d of %d bytes", b.Len(), len(src))
+	}
+}
+
+func BenchmarkLexGenerated(b *testing.B) {
+	for _, size := range []int{64 << 10, 1 << 20, 8 << 20} {
+		src := generateSource(size)
+		b.Run(fmt.Sprintf("reader/%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := lex(bytes.NewReader(src), defaultLexMe()...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("bytes/%dKB", size>>10), func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := lexBytes(src, defaultLexMe()...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package lexer
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Limits bound the work lexing untrusted input may take. A zero field means
+// no limit.
+type Limits struct {
+	// MaxSize is the largest source accepted, in bytes.
+	MaxSize int
+
+	// MaxTokens is the largest number of tokens lexed.
+	MaxTokens int
+
+	// MaxDepth is how deeply parentheses, brackets and braces may nest. The
+	// lexer does not need it, but the parser recurses that deep.
+	MaxDepth int
+}
+
+// LimitError is returned when lexing runs into one of its limits.
+type LimitError struct {
+	// Limit is the name of the limit, as in Limits.
+	Limit string
+	Max   int
+
+	// Line and Column are where lexing stopped, both zero based. Columns
+	// are counted in bytes.
+	Line   int
+	Column int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("lexer: %s of %d exceeded at line %d: column %d", e.Limit, e.Max, e.Line, e.Column)
+}
+
+// cancelEvery is how many tokens are lexed between checks for cancellation.
+const cancelEvery = 128
+
+// HighlightLimited is like Highlight for untrusted input. It reads the source
+// from r and stops with a *LimitError when it goes over l, or with the error
+// of c once c is done; nothing is written to w then.
+func HighlightLimited(c stdcontext.Context, w io.Writer, r io.Reader, f Formatter, l Limits) error {
+	src, err := readLimited(r, l)
+	if err != nil {
+		return err
+	}
+	tks, err := lexLimited(c, src, l, defaultLexMe()...)
+	if err != nil {
+		var e *LimitError
+		if errors.As(err, &e) || errors.Is(err, c.Err()) {
+			return err
+		}
+		// otherwise the rest is input the lexer can not handle, written as
+		// plain text like Highlight does
+	}
+	offset, err := format(w, tks, f)
+	if err != nil {
+		return err
+	}
+	if offset < len(src) {
+		return f.Format(w, CategoryPlain, src[offset:])
+	}
+	return nil
+}
+
+// readLimited reads all of r, but no more than l.MaxSize bytes. The source is
+// read straight into the string the tokens are lexed from.
+func readLimited(r io.Reader, l Limits) (string, error) {
+	if l.MaxSize > 0 {
+		r = io.LimitReader(r, int64(l.MaxSize)+1)
+	}
+	var b strings.Builder
+	if _, err := io.Copy(&b, r); err != nil {
+		return "", err
+	}
+	if l.MaxSize > 0 && b.Len() > l.MaxSize {
+		return "", &LimitError{Limit: "MaxSize", Max: l.MaxSize}
+	}
+	return b.String(), nil
+}
+
+// lexLimited is like lexBytes, but stops with a *LimitError when src goes
+// over l, and with the error of c once it is done.
+func lexLimited(c stdcontext.Context, src string, l Limits, lexmes ...lexMe) ([]*token, error) {
+	if l.MaxSize > 0 && len(src) > l.MaxSize {
+		return nil, &LimitError{Limit: "MaxSize", Max: l.MaxSize}
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	var tokens []*token
+	var err error
+	depth := 0
+	lexErr := lexEach(newStringScanner(src), nil, lexmes, func(tk *token) bool {
+		if len(tokens)%cancelEvery == 0 {
+			if err = c.Err(); err != nil {
+				return false
+			}
+		}
+		if l.MaxTokens > 0 && len(tokens) == l.MaxTokens {
+			err = &LimitError{Limit: "MaxTokens", Max: l.MaxTokens, Line: tk.Start.Line, Column: tk.Start.Column}
+			return false
+		}
+		switch tk.Kind {
+		case LPAREN, LBRACK, LBRACE:
+			depth++
+			if l.MaxDepth > 0 && depth > l.MaxDepth {
+				err = &LimitError{Limit: "MaxDepth", Max: l.MaxDepth, Line: tk.Start.Line, Column: tk.Start.Column}
+				return false
+			}
+		case RPAREN, RBRACK, RBRACE:
+			if depth > 0 {
+				depth--
+			}
+		}
+		tokens = append(tokens, tk)
+		return true
+	})
+	if err != nil {
+		return tokens, err
+	}
+	return tokens, lexErr
+}
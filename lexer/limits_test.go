@@ -0,0 +1,162 @@
+package lexer
+
+import (
+	"bytes"
+	stdcontext "context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLexLimited(t *testing.T) {
+	src := "f(a, [b, {c: (d)}]);\n"
+	sample := []struct {
+		limits Limits
+		limit  string
+		column int
+	}{
+		{Limits{}, "", 0},
+		{Limits{MaxSize: len(src), MaxTokens: 21, MaxDepth: 4}, "", 0},
+		{Limits{MaxSize: len(src) - 1}, "MaxSize", 0},
+		{Limits{MaxTokens: 20}, "MaxTokens", 20},
+		{Limits{MaxDepth: 3}, "MaxDepth", 13},
+	}
+	for _, v := range sample {
+		tks, err := lexLimited(stdcontext.Background(), src, v.limits, defaultLexMe()...)
+		if v.limit == "" {
+			if err != nil {
+				t.Errorf("%+v: %v", v.limits, err)
+			}
+			if len(tks) != 21 {
+				t.Errorf("%+v: expected 21 tokens got %d", v.limits, len(tks))
+			}
+			continue
+		}
+		var e *LimitError
+		if !errors.As(err, &e) {
+			t.Errorf("%+v: expected a limit error got %v", v.limits, err)
+			continue
+		}
+		if e.Limit != v.limit {
+			t.Errorf("%+v: expected %s got %s", v.limits, v.limit, e.Limit)
+		}
+		if e.Line != 0 || e.Column != v.column {
+			t.Errorf("%+v: expected to stop at column %d got line %d column %d",
+				v.limits, v.column, e.Line, e.Column)
+		}
+	}
+}
+
+func TestLexLimitedCancel(t *testing.T) {
+	src := strings.Repeat("a + b;\n", 1000)
+	c, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+	_, err := lexLimited(c, src, Limits{}, defaultLexMe()...)
+	if err != stdcontext.Canceled {
+		t.Errorf("expected %v got %v", stdcontext.Canceled, err)
+	}
+
+	// cancelled half way through
+	c, cancel = stdcontext.WithCancel(stdcontext.Background())
+	n := 0
+	lexmes := append([]lexMe{cancelingLexMe{lexMe: identifierNameLexer{}, n: &n, at: 500, cancel: cancel}},
+		defaultLexMe()...)
+	tks, err := lexLimited(c, src, Limits{}, lexmes...)
+	if err != stdcontext.Canceled {
+		t.Errorf("expected %v got %v", stdcontext.Canceled, err)
+	}
+	if len(tks) == 0 || len(tks) >= 6000 {
+		t.Errorf("expected lexing to stop early, got %d tokens", len(tks))
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	src := "var a = 1;\n"
+	for _, max := range []int{0, len(src), len(src) + 1} {
+		got, err := readLimited(strings.NewReader(src), Limits{MaxSize: max})
+		if err != nil {
+			t.Fatalf("MaxSize %d: %v", max, err)
+		}
+		if got != src {
+			t.Errorf("MaxSize %d: expected %q got %q", max, src, got)
+		}
+	}
+
+	// the reader is not read past the limit
+	r := &countingReader{r: strings.NewReader(strings.Repeat(src, 1<<10))}
+	_, err := readLimited(r, Limits{MaxSize: len(src)})
+	var e *LimitError
+	if !errors.As(err, &e) || e.Limit != "MaxSize" {
+		t.Fatalf("expected a MaxSize error got %v", err)
+	}
+	if r.n > len(src)+1 {
+		t.Errorf("expected to read at most %d bytes got %d", len(src)+1, r.n)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += n
+	return n, err
+}
+
+func TestHighlightLimited(t *testing.T) {
+	src := "f(a, [b]); @"
+	var b bytes.Buffer
+	err := HighlightLimited(stdcontext.Background(), &b, strings.NewReader(src), plainFormatter{}, Limits{MaxDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != src {
+		t.Errorf("expected %q got %q", src, b.String())
+	}
+
+	sample := []struct {
+		limits Limits
+		limit  string
+	}{
+		{Limits{MaxSize: 4}, "MaxSize"},
+		{Limits{MaxTokens: 4}, "MaxTokens"},
+		{Limits{MaxDepth: 1}, "MaxDepth"},
+	}
+	for _, v := range sample {
+		b.Reset()
+		err := HighlightLimited(stdcontext.Background(), &b, strings.NewReader(src), plainFormatter{}, v.limits)
+		var e *LimitError
+		if !errors.As(err, &e) || e.Limit != v.limit {
+			t.Errorf("%+v: expected a %s error got %v", v.limits, v.limit, err)
+		}
+		if b.Len() != 0 {
+			t.Errorf("%+v: expected nothing written got %q", v.limits, b.String())
+		}
+	}
+
+	c, cancel := stdcontext.WithCancel(stdcontext.Background())
+	cancel()
+	err = HighlightLimited(c, &b, strings.NewReader(src), plainFormatter{}, Limits{})
+	if err != stdcontext.Canceled {
+		t.Errorf("expected %v got %v", stdcontext.Canceled, err)
+	}
+}
+
+// cancelingLexMe calls cancel after lexing at tokens.
+type cancelingLexMe struct {
+	lexMe
+	n      *int
+	at     int
+	cancel func()
+}
+
+func (c cancelingLexMe) Lex(s scanner, ctx *context) (*token, error) {
+	*c.n++
+	if *c.n == c.at {
+		c.cancel()
+	}
+	return c.lexMe.Lex(s, ctx)
+}
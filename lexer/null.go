@@ -11,6 +11,10 @@ func (nullLexer) Name() string {
 	return "null"
 }
 
+func (nullLexer) canStart(ch rune) bool {
+	return ch == 'n'
+}
+
 func (nullLexer) Accept(s scanner) bool {
 	n, _, err := s.Peek()
 	if err != nil {
@@ -36,27 +40,17 @@ func (n nullLexer) Lex(s scanner, ctx *context) (*token, error) {
 	if ctx.lastToken != nil {
 		start, end = ctx.lastToken.End, start
 	}
-	nx, w, err := s.Next()
-	if err != nil {
-		return nil, err
-	}
-	chrs := string(nx)
-	end.Column += w
-	for i := 0; i < 3; i++ {
-		nx, w, err = s.Next()
+	for i := 0; i < 4; i++ {
+		_, w, err := s.Next()
 		if err != nil {
 			return nil, err
 		}
 		end.Column += w
-		chrs += string(nx)
 	}
-	if chrs == "null" {
-		return &token{
-			Text:  chrs,
-			Kind:  NULL,
-			Start: start,
-			End:   end,
-		}, nil
+	if text := s.Text(); text == "null" {
+		tk := ctx.newToken(start)
+		tk.Text, tk.Kind, tk.End = text, NULL, end
+		return tk, nil
 	}
 	return nil, fmt.Errorf(unexpectedTkn, n.Name(), end)
 }
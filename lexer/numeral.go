@@ -11,6 +11,10 @@ func (numeralLexer) Name() string {
 	return "numeral"
 }
 
+func (numeralLexer) canStart(ch rune) bool {
+	return isDecimalDigit(ch) || ch == '.'
+}
+
 func (numeralLexer) Accept(s scanner) bool {
 	ch, _, err := s.Peek()
 	if err != nil {
@@ -72,177 +76,137 @@ func isHexDigit(ch rune) bool {
 }
 
 func (n numeralLexer) Lex(s scanner, ctx *context) (*token, error) {
+	tk, err := n.lex(s, ctx)
+	if err != nil {
+		return nil, err
+	}
+	tk.Text = s.Text()
+	return tk, nil
+}
+
+func (n numeralLexer) lex(s scanner, ctx *context) (*token, error) {
 	var start position
 	if ctx.lastToken != nil {
 		start = ctx.lastToken.End
 	}
-	nx, _, err := s.Next()
+	tk := ctx.newToken(start)
+	ch, _, err := s.Next()
 	if err != nil {
 		return nil, err
 	}
-	tk := newToken(start)
-	if isDecimalDigit(nx) || isFloat(s, nx) {
-		tk.AddRune(nx)
+	tk.extend(ch)
+	tk.Kind = INT
+	if ch == '0' {
 		nxt, _, err := s.Peek()
-		if err != nil {
-			if err == io.EOF {
-				tk.Kind = INT
-				return tk, nil
-			}
+		if err != nil && err != io.EOF {
 			return nil, err
 		}
-		switch {
-		case isTokenSep(nxt):
-			s.Next()
-			tk.Kind = INT
-			return tk, nil
-		case nxt == '.':
+		var isDigit func(rune) bool
+		switch nxt {
+		case 'x', 'X':
+			tk.Kind, isDigit = HEX, isHexDigit
+		case 'b', 'B':
+			tk.Kind, isDigit = BINARY, isBinaryDigit
+		case 'o', 'O':
+			tk.Kind, isDigit = OCTAL, isOctalDigit
+		}
+		if isDigit != nil {
 			s.Next()
-			tk.AddRune(nxt)
-			ch, _, err := s.Peek()
+			tk.extend(nxt)
+			c, err := n.digits(s, tk, isDigit)
 			if err != nil {
 				return nil, err
 			}
-			switch {
-			case isDecimalDigit(ch):
-				s.Next()
-				tk.AddRune(ch)
-				for {
-					ch, _, err = s.Peek()
-					if err != nil {
-						if err == io.EOF {
-							break
-						} else {
-							return nil, err
-						}
-					}
-					if isDecimalDigit(ch) {
-						s.Next()
-						tk.AddRune(ch)
-						continue
-					}
-					if ch == 'e' || ch == 'E' {
-						s.Next()
-						tk.AddRune(ch)
-						ch, _, err = s.Next()
-						if err != nil {
-							return nil, err
-						}
-						if isDecimalDigit(ch) || ch == '+' || ch == '-' {
-							tk.AddRune(ch)
-							continue
-						}
-						return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
-					}
-					if isTokenSep(ch) {
-						break
-					}
-					return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
-				}
-				return tk, nil
-			}
-		case nx == '0' && nxt == 'x' || nx == 0 && nxt == 'X':
-			tk.Kind = HEX
-			s.Next()
-			tk.AddRune(nxt)
-			for {
-				ch, _, err := s.Peek()
-				if err != nil {
-					if err == io.EOF {
-						break
-					} else {
-						return nil, err
-					}
-				}
-				if isHexDigit(ch) {
-					s.Next()
-					tk.AddRune(ch)
-					continue
-				}
-				break
-			}
-			return tk, nil
-		case nx == '0' && nxt == 'b' || nxt == 'B':
-			tk.Kind = BINARY
-			for {
-				ch, _, err := s.Next()
-				if err != nil {
-					if err == io.EOF {
-						break
-					} else {
-						return nil, err
-					}
-				}
-				if isBinaryDigit(ch) {
-					tk.AddRune(ch)
-					continue
-				}
-				if isTokenSep(ch) {
-					s.Rewind()
-					break
-				}
-				return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
-			}
-			return tk, nil
-		case nx == '0' && nxt == 'o' || nxt == 'O':
-			tk.Kind = OCTAL
-			for {
-				ch, _, err := s.Next()
-				if err != nil {
-					if err == io.EOF {
-						break
-					} else {
-						return nil, err
-					}
-				}
-				if isOctalDigit(ch) {
-					tk.AddRune(ch)
-					continue
-				}
-				if isTokenSep(ch) {
-					s.Rewind()
-					break
-				}
+			if c == 0 {
 				return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
 			}
+			return n.end(s, tk)
+		}
+	}
+	if ch == '.' {
+		tk.Kind = FLOAT
+	}
+	if _, err := n.digits(s, tk, isDecimalDigit); err != nil {
+		return nil, err
+	}
+	nxt, _, err := s.Peek()
+	if err == io.EOF {
+		return tk, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nxt == '.' && tk.Kind == INT {
+		// 1. is a number too, as in 1..toString()
+		s.Next()
+		tk.extend(nxt)
+		tk.Kind = FLOAT
+		if _, err := n.digits(s, tk, isDecimalDigit); err != nil {
+			return nil, err
+		}
+		nxt, _, err = s.Peek()
+		if err == io.EOF {
 			return tk, nil
-		case isDecimalDigit(nxt):
-			tk.Kind = INT
-			for {
-				ch, _, err := s.Next()
-				if err != nil {
-					if err == io.EOF {
-						break
-					} else {
-						return nil, err
-					}
-				}
-				if isDecimalDigit(ch) {
-					tk.AddRune(ch)
-					continue
-				}
-				if ch == 'e' || ch == 'E' {
-					tk.AddRune(ch)
-					ch, _, err = s.Next()
-					if err != nil {
-						return nil, err
-					}
-					if isDecimalDigit(ch) || ch == '+' || ch == '-' {
-						tk.AddRune(ch)
-						continue
-					}
-					return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
-				}
-				if isTokenSep(ch) {
-					break
-				}
-				return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if nxt == 'e' || nxt == 'E' {
+		s.Next()
+		tk.extend(nxt)
+		tk.Kind = FLOAT
+		sign, _, err := s.Peek()
+		if err == nil && (sign == '+' || sign == '-') {
+			s.Next()
+			tk.extend(sign)
+		}
+		c, err := n.digits(s, tk, isDecimalDigit)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
+		}
+	}
+	return n.end(s, tk)
+}
+
+// digits reads the digits accepted by isDigit into tk and returns how many
+// there were.
+func (n numeralLexer) digits(s scanner, tk *token, isDigit func(rune) bool) (int, error) {
+	c := 0
+	for {
+		ch, _, err := s.Peek()
+		if err != nil {
+			if err == io.EOF {
+				return c, nil
 			}
-			return tk, nil
-		default:
-			tk.Kind = INT
+			return c, err
+		}
+		if !isDigit(ch) {
+			return c, nil
+		}
+		s.Next()
+		tk.extend(ch)
+		c++
+	}
+}
+
+// end returns tk, the numeric literal read so far, unless an identifier or a
+// digit follows straight after it.
+//
+// https://tc39.es/ecma262/#sec-literals-numeric-literals
+func (n numeralLexer) end(s scanner, tk *token) (*token, error) {
+	ch, _, err := s.Peek()
+	if err != nil {
+		if err == io.EOF {
 			return tk, nil
 		}
+		return nil, err
+	}
+	if isDecimalDigit(ch) || isUnicodeIDStart(ch) || ch == '$' || ch == '_' || ch == reverseSolidus {
+		return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
 	}
-	return nil, fmt.Errorf(unexpectedTkn, n.Name(), tk.End)
+	return tk, nil
 }
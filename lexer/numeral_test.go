@@ -25,3 +25,76 @@ func TestNumeralLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestNumeralLexerEnd(t *testing.T) {
+	var l numeralLexer
+	sample := []struct {
+		src, text string
+		kind      kind
+	}{
+		{"10;", "10", INT},
+		{"12)", "12", INT},
+		{"10]", "10", INT},
+		{"1.5;", "1.5", FLOAT},
+		{"1..toString()", "1.", FLOAT},
+		{".5+1", ".5", FLOAT},
+		{"1e+10,", "1e+10", FLOAT},
+		{"0xFf}", "0xFf", HEX},
+		{"0b101 ", "0b101", BINARY},
+		{"0O17\n", "0O17", OCTAL},
+		{"42/2", "42", INT},
+		{"7.", "7.", FLOAT},
+	}
+	for _, v := range sample {
+		s := newBytesScanner([]byte(v.src))
+		tk, err := l.Lex(s, &context{})
+		if err != nil {
+			t.Errorf("%q: %v", v.src, err)
+			continue
+		}
+		if tk.Text != v.text || tk.Kind != v.kind {
+			t.Errorf("%q: expected %q %s got %q %s", v.src, v.text, v.kind, tk.Text, tk.Kind)
+		}
+	}
+
+	bad := []string{"3in", "10x", "0x", "0xg", "0b12", "0o8", "1e", "1e+", "1.5$", "2_"}
+	for _, v := range bad {
+		s := newBytesScanner([]byte(v))
+		if _, err := l.Lex(s, &context{}); err == nil {
+			t.Errorf("expected an error for %q", v)
+		}
+	}
+}
+
+func TestNumeralLeadingDot(t *testing.T) {
+	sample := []struct {
+		src    string
+		tokens []string
+	}{
+		{"x = .5;", []string{"x", " ", "=", " ", ".5", ";"}},
+		{"f(.5)", []string{"f", "(", ".5", ")"}},
+		{"a.b", []string{"a", ".", "b"}},
+		{"[...a]", []string{"[", "...", "a", "]"}},
+		{"1..toString()", []string{"1.", ".", "toString", "(", ")"}},
+	}
+	for _, v := range sample {
+		tokens := lexTexts(t, v.src)
+		if strings.Join(tokens, "|") != strings.Join(v.tokens, "|") {
+			t.Errorf("%s: expected %q got %q", v.src, v.tokens, tokens)
+		}
+	}
+	tks, err := lexBytes([]byte("x = .5;"), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tks[4].Kind != FLOAT {
+		t.Errorf("expected .5 to be %s got %s", FLOAT, tks[4].Kind)
+	}
+}
+
+func BenchmarkNumeralLexer(b *testing.B) {
+	benchmarkLexer(b, numeralLexer{}, []string{
+		"0", "3", "3.14", "314", "1234567890", "123e5", "123e-5", "0.5e2",
+		"0xFF", "0xDEADBEEF", "0b1010", "0o777", "1.",
+	})
+}
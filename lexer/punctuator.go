@@ -3,6 +3,7 @@ package lexer
 import (
 	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 var punctuation = map[string]bool{
@@ -55,6 +56,7 @@ var punctuation = map[string]bool{
 	"|=":   true,
 	"^=":   true,
 	"=>":   true,
+	"@":    true,
 	"/":    true,
 	"}":    true,
 }
@@ -109,29 +111,58 @@ var punctuationKind = map[string]kind{
 	"|=":   OrAssign,
 	"^=":   XorAssign,
 	"=>":   ARROW,
+	"@":    AT,
 	"/":    QUO,
 	"}":    RBRACE,
 }
 
+// punctuationStart marks the ASCII characters punctuation starts with.
+var punctuationStart = func() (start [utf8.RuneSelf]bool) {
+	for p := range punctuation {
+		start[p[0]] = true
+	}
+	return start
+}()
+
 type punctuationLexer struct{}
 
 func (punctuationLexer) Name() string {
 	return "punctuation"
 }
 
+func (punctuationLexer) canStart(ch rune) bool {
+	return isPunctuation(ch)
+}
+
 func (punctuationLexer) Accept(s scanner) bool {
 	ch, _, err := s.Peek()
 	if err != nil {
 		return false
 	}
-	return isPunctuation(string(ch))
+	if ch == '.' {
+		// .5 is a number
+		nxt, _, err := s.PeekAt(2)
+		if err == nil && isDecimalDigit(nxt) {
+			return false
+		}
+	}
+	return isPunctuation(ch)
 }
 
-func isPunctuation(ch string) bool {
-	return punctuation[ch]
+func isPunctuation(ch rune) bool {
+	return 0 <= ch && ch < utf8.RuneSelf && punctuationStart[ch]
 }
 
 func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
+	tk, err := p.lex(s, ctx)
+	if err != nil {
+		return nil, err
+	}
+	tk.Text = s.Text()
+	return tk, nil
+}
+
+func (p punctuationLexer) lex(s scanner, ctx *context) (*token, error) {
 	var start position
 	if ctx.lastToken != nil {
 		start = ctx.lastToken.End
@@ -140,8 +171,8 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 	if err != nil {
 		return nil, err
 	}
-	tk := newToken(start)
-	tk.AddRune(nx)
+	tk := ctx.newToken(start)
+	tk.extend(nx)
 
 	switch nx {
 	case '{':
@@ -176,7 +207,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			switch nxt {
 			case '.':
 				s.Next()
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				nxt, _, err = s.Next()
 				if err != nil {
 					return nil, err
@@ -184,7 +215,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 				if nxt != '.' {
 					return nil, fmt.Errorf(unexpectedTkn, p.Name(), tk.End)
 				}
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				tk.Kind = ELLIPSIS
 			}
 		}
@@ -212,10 +243,10 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '<':
 				s.Next()
 				tk.Kind = SHL
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 
 				if p.Accept(s) {
-					nxt, _, err = s.Next()
+					nxt, _, err = s.Peek()
 					if err == io.EOF {
 						return tk, nil
 					}
@@ -225,7 +256,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 					if nxt == '=' {
 						s.Next()
 						tk.Kind = SHLAssign
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 					}
 				}
 
@@ -233,7 +264,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 				// We advance the cursor since we already read the rune thorugh peek.
 				s.Next()
 				tk.Kind = LEQ
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
@@ -252,11 +283,11 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 				// We advance the cursor since we already read the rune thorugh peek.
 				s.Next()
 				tk.Kind = GEQ
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '>': //>>
 				s.Next()
 				tk.Kind = SHR
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				if p.Accept(s) {
 					nxt, _, err = s.Peek()
 					if err == io.EOF {
@@ -270,11 +301,11 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 					case '=': //>>=
 						tk.Kind = SHRAssign
 						s.Next()
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 					case '>': //>>>
 						tk.Kind = USHR
 						s.Next()
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 						if p.Accept(s) {
 							nxt, _, err = s.Peek()
 							if err == io.EOF {
@@ -286,7 +317,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 							if nxt == '=' { ///>>>=
 								s.Next()
 								tk.Kind = USHRAssign
-								tk.AddRune(nxt)
+								tk.extend(nxt)
 							}
 						}
 					}
@@ -308,18 +339,18 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '=':
 				s.Next()
 				tk.Kind = AddAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '+':
 				s.Next()
 				tk.Kind = INC
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
 	case '-':
 		tk.Kind = SUB
 		if p.Accept(s) {
-			nxt, _, err := s.Next()
+			nxt, _, err := s.Peek()
 			if err == io.EOF {
 				return tk, nil
 			}
@@ -330,11 +361,11 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '-':
 				s.Next()
 				tk.Kind = DEC
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '=':
 				s.Next()
 				tk.Kind = SubAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
@@ -352,11 +383,11 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '=':
 				s.Next()
 				tk.Kind = MulAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '*':
 				s.Next()
 				tk.Kind = EXP
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 
 				if p.Accept(s) {
 					nxt, _, err := s.Peek()
@@ -370,7 +401,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 					case '=':
 						s.Next()
 						tk.Kind = ExpAssign
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 					}
 				}
 			}
@@ -390,7 +421,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '=':
 				s.Next()
 				tk.Kind = RemAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
@@ -408,11 +439,11 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '=':
 				s.Next()
 				tk.Kind = AndAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '&':
 				s.Next()
 				tk.Kind = LAND
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
@@ -430,18 +461,18 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '|':
 				s.Next()
 				tk.Kind = LOR
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '=':
 				s.Next()
 				tk.Kind = OrAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
 	case '^':
 		tk.Kind = XOR
 		if p.Accept(s) {
-			nxt, _, err := s.Next()
+			nxt, _, err := s.Peek()
 			if err == io.EOF {
 				return tk, nil
 			}
@@ -452,7 +483,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '=':
 				s.Next()
 				tk.Kind = XorAssign
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			}
 		}
 		return tk, nil
@@ -468,7 +499,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 				// We advance the cursor since we already read the rune thorugh peek.
 				s.Next()
 				tk.Kind = NEQ
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				if p.Accept(s) {
 					nxt, _, err = s.Peek()
 					if err == io.EOF {
@@ -480,7 +511,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 					if nxt == '=' {
 						s.Next()
 						tk.Kind = SNEQ
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 					}
 				}
 			}
@@ -492,6 +523,9 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 	case '?':
 		tk.Kind = QN
 		return tk, nil
+	case '@':
+		tk.Kind = AT
+		return tk, nil
 	case ':':
 		tk.Kind = COLON
 		return tk, nil
@@ -509,12 +543,12 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 			case '>':
 				s.Next()
 				tk.Kind = ARROW
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 			case '=':
 				// We advance the cursor since we already read the rune thorugh peek.
 				s.Next()
 				tk.Kind = EQL
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				if p.Accept(s) {
 					nxt, _, err = s.Peek()
 					if err == io.EOF {
@@ -526,7 +560,7 @@ func (p punctuationLexer) Lex(s scanner, ctx *context) (*token, error) {
 					if nxt == '=' {
 						s.Next()
 						tk.Kind = SEQL
-						tk.AddRune(nxt)
+						tk.extend(nxt)
 					}
 				}
 			}
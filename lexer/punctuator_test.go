@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"sort"
 	"strings"
 	"testing"
 )
@@ -25,3 +26,36 @@ func TestPunctuationLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestPunctuationLexerPeek(t *testing.T) {
+	sample := []struct {
+		src    string
+		tokens []string
+	}{
+		{"a-b", []string{"a", "-", "b"}},
+		{"a--", []string{"a", "--"}},
+		{"a-=1", []string{"a", "-=", "1"}},
+		{"-1", []string{"-", "1"}},
+		{"a^b", []string{"a", "^", "b"}},
+		{"a^=b", []string{"a", "^=", "b"}},
+		{"a<<b", []string{"a", "<<", "b"}},
+		{"a<<=b", []string{"a", "<<=", "b"}},
+		{"a<b", []string{"a", "<", "b"}},
+		{"a<=b", []string{"a", "<=", "b"}},
+	}
+	for _, v := range sample {
+		tokens := lexTexts(t, v.src)
+		if strings.Join(tokens, " ") != strings.Join(v.tokens, " ") {
+			t.Errorf("%s: expected %q got %q", v.src, v.tokens, tokens)
+		}
+	}
+}
+
+func BenchmarkPunctuationLexer(b *testing.B) {
+	var samples []string
+	for v := range punctuation {
+		samples = append(samples, v)
+	}
+	sort.Strings(samples)
+	benchmarkLexer(b, punctuationLexer{}, samples)
+}
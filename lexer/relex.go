@@ -0,0 +1,101 @@
+package lexer
+
+import (
+	"unicode/utf8"
+
+	"github.com/gernest/chapman/edit"
+)
+
+// maxLookahead is how far, in bytes, lexers may peek past the end of a token
+// to decide where it ends: at most two runes. Tokens ending this close to an
+// edit may lex differently once it is applied.
+const maxLookahead = 2 * utf8.UTFMax
+
+// relex updates tks, the tokens of a source before e was applied to it, to
+// the tokens of src, the source after the edit. It is internal for now: the
+// token type it works on is not exported yet.
+//
+// Lexing restarts at the token containing the first byte that could be
+// affected by the edit and stops as soon as a freshly lexed token matches an
+// old token lying after the edited range, at the same place once the edit is
+// accounted for. The lexers carry no state besides the previous token, so
+// every old token from there on is reused with its position shifted. Tokens
+// that keep their position are shared with tks.
+func relex(tks []*token, src []byte, e edit.TextEdit, lexmes ...lexMe) ([]*token, error) {
+	delta := len(e.NewText) - (e.End - e.Start)
+
+	// from is the byte offset of tks[restart] in the old source
+	restart, from := len(tks), 0
+	for i, tk := range tks {
+		if from+len(tk.Text) > e.Start-maxLookahead {
+			restart = i
+			break
+		}
+		from += len(tk.Text)
+	}
+	var last *token
+	if restart > 0 {
+		last = tks[restart-1]
+	}
+	if from > len(src) {
+		from = len(src)
+	}
+
+	result := make([]*token, restart, len(tks))
+	copy(result, tks[:restart])
+	resync := -1
+	// old is the old token lexing has reached, starting at oldStart
+	old, oldStart := restart, from
+	offset := from
+	err := lexEach(newBytesScannerAt(src, from), last, lexmes, func(tk *token) bool {
+		result = append(result, tk)
+		offset += len(tk.Text)
+		// the same offset in the old source
+		o := offset - delta
+		for old < len(tks) && oldStart+len(tks[old].Text) < o {
+			oldStart += len(tks[old].Text)
+			old++
+		}
+		if old < len(tks) && oldStart+len(tks[old].Text) == o && oldStart >= e.End &&
+			tks[old].Kind == tk.Kind && tks[old].Text == tk.Text {
+			resync = old
+			return false
+		}
+		return true
+	})
+	if err != nil || resync == -1 {
+		return result, err
+	}
+
+	// Tokens are shared with tks, so the ones that move are copied, together
+	// in one block. When the edit leaves the line count alone, only the
+	// tokens on the line it ends on move.
+	oldEnd, newEnd := tks[resync].End, result[len(result)-1].End
+	rest := tks[resync+1:]
+	n := len(rest)
+	if oldEnd.Line == newEnd.Line {
+		n = 0
+		for n < len(rest) && rest[n].Start.Line == oldEnd.Line {
+			n++
+		}
+	}
+	shifted := make([]token, n)
+	for i, tk := range rest[:n] {
+		shifted[i] = *tk
+		shifted[i].Start = shift(tk.Start, oldEnd, newEnd)
+		shifted[i].End = shift(tk.End, oldEnd, newEnd)
+		result = append(result, &shifted[i])
+	}
+	result = append(result, rest[n:]...)
+	return result, nil
+}
+
+// shift moves p, a position at or after from, by the distance between from
+// and to.
+func shift(p, from, to position) position {
+	if p.Line == from.Line {
+		p.Column += to.Column - from.Column
+	}
+	p.Line += to.Line - from.Line
+	return p
+}
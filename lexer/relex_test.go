@@ -0,0 +1,141 @@
+package lexer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gernest/chapman/edit"
+)
+
+type countingLexMe struct {
+	lexMe
+	n *int
+}
+
+func (c countingLexMe) Lex(s scanner, ctx *context) (*token, error) {
+	*c.n++
+	return c.lexMe.Lex(s, ctx)
+}
+
+func TestRelex(t *testing.T) {
+	src := []byte("var a = 1;\n/* one\ntwo */ a += \"x\"; // end\nb = a\n")
+	sample := []edit.TextEdit{
+		{Start: 4, End: 5, NewText: "abc"},
+		{Start: 4, End: 5, NewText: "a\n\n"},
+		{Start: 8, End: 9, NewText: "12"},
+		{Start: 0, End: 0, NewText: "let x\n"},
+		{Start: 11, End: 11, NewText: "/"},
+		{Start: 12, End: 12, NewText: "/"},
+		{Start: 17, End: 17, NewText: "*/"},
+		{Start: 11, End: 24, NewText: ""},
+		{Start: 30, End: 30, NewText: "y"},
+		{Start: 34, End: 34, NewText: "\n"},
+		{Start: 48, End: 48, NewText: " + 2"},
+		{Start: 9, End: 9, NewText: "@"},
+	}
+	old, err := lex(bytes.NewReader(src), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range sample {
+		after, _, err := edit.Apply(src, []edit.TextEdit{e})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect, expectErr := lex(bytes.NewReader(after), defaultLexMe()...)
+		got, err := relex(old, after, e, defaultLexMe()...)
+		if (err == nil) != (expectErr == nil) {
+			t.Errorf("%v: expected error %v got %v", e, expectErr, err)
+			continue
+		}
+		if a, b := string(printTokensCopy(got)), string(printTokensCopy(expect)); a != b {
+			t.Errorf("%v: expected %s got %s", e, b, a)
+		}
+	}
+}
+
+// printTokensCopy prints tks without touching the tokens, which may be shared
+// with other token lists.
+func printTokensCopy(tks []*token) []byte {
+	c := make([]*token, len(tks))
+	for i, tk := range tks {
+		n := *tk
+		c[i] = &n
+	}
+	b, _ := printTokens(c)
+	return b
+}
+
+func TestRelexResync(t *testing.T) {
+	src, err := ioutil.ReadFile("fixture/comments/basic/block-trailing-comment/actual.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	for i := 0; i < 200; i++ {
+		b.Write(src)
+	}
+	src = b.Bytes()
+	old, err := lex(bytes.NewReader(src), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := edit.TextEdit{Start: len(src) / 2, End: len(src) / 2, NewText: "x"}
+	after, _, err := edit.Apply(src, []edit.TextEdit{e})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	var lexmes []lexMe
+	for _, v := range defaultLexMe() {
+		lexmes = append(lexmes, countingLexMe{lexMe: v, n: &n})
+	}
+	got, err := relex(old, after, e, lexmes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n > 10 {
+		t.Errorf("expected to relex a few tokens got %d of %d", n, len(got))
+	}
+	expect, err := lex(bytes.NewReader(after), defaultLexMe()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := string(printTokensCopy(got)), string(printTokensCopy(expect)); a != b {
+		t.Errorf("expected %s got %s", b, a)
+	}
+}
+
+func BenchmarkRelex(b *testing.B) {
+	src := generateSource(4 << 20)
+	old, err := lexBytes(src, defaultLexMe()...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, text := range []string{" ", "\n"} {
+		e := edit.TextEdit{Start: len(src) / 2, End: len(src) / 2, NewText: text}
+		after, _, err := edit.Apply(src, []edit.TextEdit{e})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("relex/%q", text), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := relex(old, after, e, defaultLexMe()...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+	b.Run("lexBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := lexBytes(src, defaultLexMe()...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"unicode/utf8"
 )
 
@@ -34,6 +35,10 @@ func isEscapeChar(ch rune) bool {
 		isDecimalDigit(ch) || ch == 'x' || ch == 'u'
 }
 
+func (stringLexer) canStart(ch rune) bool {
+	return ch == '"' || ch == '\''
+}
+
 func (stringLexer) Accept(s scanner) bool {
 	ch, _, er := s.Peek()
 	if er != nil {
@@ -43,6 +48,15 @@ func (stringLexer) Accept(s scanner) bool {
 }
 
 func (sl stringLexer) Lex(s scanner, ctx *context) (*token, error) {
+	tk, err := sl.lex(s, ctx)
+	if err != nil {
+		return nil, err
+	}
+	tk.Text = s.Text()
+	return tk, nil
+}
+
+func (sl stringLexer) lex(s scanner, ctx *context) (*token, error) {
 	var start position
 	if ctx.lastToken != nil {
 		start = ctx.lastToken.End
@@ -51,9 +65,10 @@ func (sl stringLexer) Lex(s scanner, ctx *context) (*token, error) {
 	if err != nil {
 		return nil, err
 	}
-	tk := newToken(start)
-	tk.AddRune(ch)
-	if ch == '"' {
+	tk := ctx.newToken(start)
+	tk.Kind = STRING
+	tk.extend(ch)
+	if ch == '"' || ch == '\'' {
 		var gerr error
 	main:
 		for {
@@ -64,9 +79,8 @@ func (sl stringLexer) Lex(s scanner, ctx *context) (*token, error) {
 				}
 				return nil, err
 			}
-			tk.AddRune(nx)
-			if nx == '"' {
-				tk.AddRune(nx)
+			tk.extend(nx)
+			if nx == ch {
 				return tk, nil
 			}
 			if nx == backSlash {
@@ -74,63 +88,38 @@ func (sl stringLexer) Lex(s scanner, ctx *context) (*token, error) {
 				if err != nil {
 					return nil, err
 				}
-				tk.AddRune(nxt)
+				tk.extend(nxt)
 				switch {
 				case nxt == '0':
-					next, _, err := s.Next()
-					if err != nil {
-						return nil, err
-					}
-					tk.AddRune(next)
-					if !isDecimalDigit(next) {
+					// \0 is the null character, but only when no digit follows it.
+					next, _, err := s.Peek()
+					if err == nil && isDecimalDigit(next) {
 						return nil, fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
 					}
 					continue
-				case nx == 'x':
-					next, _, err := s.Next()
-					if err != nil {
+				case nxt == 'x':
+					if err := sl.hex(s, tk, 2); err != nil {
 						return nil, err
 					}
-					tk.AddRune(next)
-					if !isHexDigit(next) {
-						return nil, fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
-					}
 					continue
 				case nxt == 'u':
-					next, _, err := s.Next()
+					next, _, err := s.Peek()
 					if err != nil {
 						return nil, err
 					}
-					tk.AddRune(next)
-					if isHexDigit(next) {
-						for i := 0; i < 3; i++ {
-							next, _, err = s.Next()
-							if err != nil {
-								return nil, err
-							}
-							tk.AddRune(next)
-							if !isHexDigit(next) {
-								return nil, fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
-							}
+					if next != '{' {
+						if err := sl.hex(s, tk, 4); err != nil {
+							return nil, err
 						}
 						continue
 					}
-					if next == '{' {
-						for {
-							next, _, err = s.Next()
-							if err != nil {
-								return nil, err
-							}
-							tk.AddRune(next)
-							if next == '}' {
-								continue
-							}
-							if !isHexDigit(next) {
-								gerr = fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
-								break main
-							}
-						}
+					s.Next()
+					tk.extend(next)
+					if err := sl.codePoint(s, tk); err != nil {
+						gerr = err
+						break main
 					}
+					continue
 				case isSingleCharacterEscape(nxt) || isNonEscapeChar(nxt):
 					continue
 				default:
@@ -144,3 +133,43 @@ func (sl stringLexer) Lex(s scanner, ctx *context) (*token, error) {
 	}
 	return nil, fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
 }
+
+// hex reads exactly n hex digits.
+func (sl stringLexer) hex(s scanner, tk *token, n int) error {
+	for i := 0; i < n; i++ {
+		ch, _, err := s.Next()
+		if err != nil {
+			return err
+		}
+		tk.extend(ch)
+		if !isHexDigit(ch) {
+			return fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
+		}
+	}
+	return nil
+}
+
+// codePoint reads the hex digits and closing } of a \u{...} escape. The
+// value must be at most 0x10FFFF.
+func (sl stringLexer) codePoint(s scanner, tk *token) error {
+	v, n := 0, 0
+	for {
+		ch, _, err := s.Next()
+		if err != nil {
+			return err
+		}
+		tk.extend(ch)
+		if ch == '}' && n > 0 {
+			return nil
+		}
+		if !isHexDigit(ch) {
+			return fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
+		}
+		d, _ := strconv.ParseUint(string(ch), 16, 8)
+		v = v*16 + int(d)
+		n++
+		if v > utf8.MaxRune {
+			return fmt.Errorf(unexpectedTkn, sl.Name(), tk.End)
+		}
+	}
+}
@@ -20,6 +20,10 @@ func TestStringLexer(t *testing.T) {
 		`"\xgg"`,
 		`"\u1"`,
 		`"Hello\1World"`,
+		`"\01"`,
+		`"\u{}"`,
+		`'\x6'`,
+		`'unterminated`,
 	}
 
 	var l stringLexer
@@ -53,6 +57,13 @@ func TestStringLexer(t *testing.T) {
 		`"a\\r\\nb"`,
 		`"\\u0451"`,
 		`"\\u0006A"`,
+		`"\x61"`,
+		`"Hello\0World"`,
+		`"\u{714E}\u{8336}"`,
+		`"\u{10FFFF}"`,
+		`'single'`,
+		`'a "quoted" \'word\''`,
+		`'\u0435\x20'`,
 	}
 	for _, v := range goodStrings {
 		s := newBufioScanner(strings.NewReader(v))
@@ -62,3 +73,44 @@ func TestStringLexer(t *testing.T) {
 		}
 	}
 }
+
+func TestStringLexerQuotes(t *testing.T) {
+	sample := []struct {
+		src  string
+		text string
+	}{
+		{`'a' + b`, `'a'`},
+		{`'a "b" c';`, `'a "b" c'`},
+		{`'it\'s'`, `'it\'s'`},
+		{`"it's"`, `"it's"`},
+		{`''`, `''`},
+		{`"a\"b" + "c"`, `"a\"b"`},
+	}
+	var l stringLexer
+	for _, v := range sample {
+		s := newBufioScanner(strings.NewReader(v.src))
+		if !l.Accept(s) {
+			t.Error("expected to accept", v.src)
+		}
+		tk, err := l.Lex(s, &context{})
+		if err != nil {
+			t.Fatalf("%s: %v", v.src, err)
+		}
+		if tk.Text != v.text {
+			t.Errorf("expected %s got %s", v.text, tk.Text)
+		}
+		if tk.Kind != STRING {
+			t.Errorf("%s: expected %s got %s", v.src, STRING, tk.Kind)
+		}
+	}
+}
+
+func BenchmarkStringLexer(b *testing.B) {
+	benchmarkLexer(b, stringLexer{}, []string{
+		`"abc"`,
+		`'single quoted'`,
+		`"a somewhat longer string, as found in messages and templates"`,
+		`"Hello\nworld"`,
+		`'日本語のテキスト'`,
+	})
+}
@@ -1,6 +1,9 @@
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"unicode/utf8"
+)
 
 type lineTerminatorLexer struct{}
 
@@ -8,6 +11,10 @@ func (lineTerminatorLexer) Name() string {
 	return "LineTerminator"
 }
 
+func (lineTerminatorLexer) canStart(ch rune) bool {
+	return ch >= utf8.RuneSelf || isLineTerminator(ch)
+}
+
 func (lineTerminatorLexer) Accept(s scanner) bool {
 	n, _, err := s.Peek()
 	if err != nil {
@@ -37,10 +44,8 @@ func (t lineTerminatorLexer) Lex(s scanner, ctx *context) (*token, error) {
 	if isLineTerminator(n) {
 		end.Line++
 		end.Column = 0
-		tk := &token{
-			Text:  string(n),
-			Start: start,
-			End:   end}
+		tk := ctx.newToken(start)
+		tk.End = end
 		switch n {
 		case 0x0000A:
 			tk.Kind = LF
@@ -60,6 +65,7 @@ func (t lineTerminatorLexer) Lex(s scanner, ctx *context) (*token, error) {
 		case 0x2029:
 			tk.Kind = PS
 		}
+		tk.Text = s.Text()
 		return tk, nil
 	}
 	return nil, fmt.Errorf(unexpectedTkn, t.Name(), end)
@@ -0,0 +1,47 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineTerminatorLexer(t *testing.T) {
+	var l lineTerminatorLexer
+	sample := []struct {
+		src  string
+		text string
+		kind kind
+	}{
+		{"\n", "\n", LF},
+		{"\r", "\r", CR},
+		{"\r\n", "\r\n", CR},
+		{"\r\r", "\r", CR},
+		{"\u2028", "\u2028", LS},
+		{"\u2029", "\u2029", PS},
+	}
+	for _, v := range sample {
+		s := newBufioScanner(strings.NewReader(v.src))
+		if !l.Accept(s) {
+			t.Errorf("expected to accept %q", v.src)
+		}
+		tk, err := l.Lex(s, &context{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tk.Text != v.text {
+			t.Errorf("%q: expected %q got %q", v.src, v.text, tk.Text)
+		}
+		if tk.Kind != v.kind {
+			t.Errorf("%q: expected %s got %s", v.src, v.kind, tk.Kind)
+		}
+		if tk.End.Line != 1 || tk.End.Column != 0 {
+			t.Errorf("%q: expected to end on the next line got %v", v.src, tk.End)
+		}
+	}
+
+	tokens := lexTexts(t, "a;\r\nb;\r\n")
+	e := []string{"a", ";", "\r\n", "b", ";", "\r\n"}
+	if strings.Join(tokens, "|") != strings.Join(e, "|") {
+		t.Errorf("expected %q got %q", e, tokens)
+	}
+}
@@ -3,15 +3,20 @@ package lexer
 import (
 	"fmt"
 	"unicode"
+	"unicode/utf8"
 )
 
 type whiteSpaceLexer struct{}
 
-func (whiteSpaceLexer) name() string {
+func (whiteSpaceLexer) Name() string {
 	return "whiteSpaceLexer"
 }
 
-func (whiteSpaceLexer) accept(s scanner) bool {
+func (whiteSpaceLexer) canStart(ch rune) bool {
+	return ch >= utf8.RuneSelf || isWhiteSpace(ch)
+}
+
+func (whiteSpaceLexer) Accept(s scanner) bool {
 	n, _, err := s.Peek()
 	if err != nil {
 		return false
@@ -28,7 +33,7 @@ func isWhiteSpace(ch rune) bool {
 	}
 }
 
-func (w whiteSpaceLexer) lex(s scanner, ctx *context) (*token, error) {
+func (w whiteSpaceLexer) Lex(s scanner, ctx *context) (*token, error) {
 	var start, end position
 	if ctx.lastToken != nil {
 		start, end = ctx.lastToken.End, start
@@ -39,11 +44,8 @@ func (w whiteSpaceLexer) lex(s scanner, ctx *context) (*token, error) {
 	}
 	end.Column += size
 	if isWhiteSpace(n) {
-		tk := &token{
-			Start: start,
-			End:   end,
-			Text:  string(n),
-		}
+		tk := ctx.newToken(start)
+		tk.End, tk.Text = end, s.Text()
 		switch n {
 		case 0x0009:
 			tk.Kind = TAB
@@ -64,5 +66,5 @@ func (w whiteSpaceLexer) lex(s scanner, ctx *context) (*token, error) {
 		}
 		return tk, nil
 	}
-	return nil, fmt.Errorf(unexpectedTkn, w.name(), end)
+	return nil, fmt.Errorf(unexpectedTkn, w.Name(), end)
 }
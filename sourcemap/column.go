@@ -0,0 +1,83 @@
+package sourcemap
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// splitLines splits text at the line terminators of javascript: LF, CR, CRLF,
+// LS and PS, the same ones the lexer counts lines with.
+func splitLines(text string) []string {
+	var lines []string
+	start := 0
+	for i := 0; i < len(text); {
+		ch, w := utf8.DecodeRuneInString(text[i:])
+		switch ch {
+		case '\n', '\u2028', '\u2029':
+			lines = append(lines, text[start:i])
+			i += w
+		case '\r':
+			lines = append(lines, text[start:i])
+			i += w
+			if strings.HasPrefix(text[i:], "\n") {
+				i++
+			}
+		default:
+			i += w
+			continue
+		}
+		start = i
+	}
+	return append(lines, text[start:])
+}
+
+// utf16Column converts col, a column in bytes into line, to UTF-16 code
+// units, the way source maps count columns. Columns past the end of line are
+// kept as far past it.
+func utf16Column(line string, col int) int {
+	n := 0
+	for i, ch := range line {
+		if i >= col {
+			return n
+		}
+		n += utf16Len(ch)
+	}
+	return n + col - len(line)
+}
+
+// byteColumn converts col, a column in UTF-16 code units into line, back to
+// bytes. It undoes utf16Column.
+func byteColumn(line string, col int) int {
+	n := 0
+	for i, ch := range line {
+		if n >= col {
+			return i
+		}
+		n += utf16Len(ch)
+	}
+	return len(line) + col - n
+}
+
+func utf16Len(ch rune) int {
+	if ch >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// lineColumns converts columns on the lines of a text, if it is known.
+type lineColumns []string
+
+func (l lineColumns) utf16(p Position) Position {
+	if p.Line < len(l) {
+		p.Column = utf16Column(l[p.Line], p.Column)
+	}
+	return p
+}
+
+func (l lineColumns) bytes(p Position) Position {
+	if p.Line < len(l) {
+		p.Column = byteColumn(l[p.Line], p.Column)
+	}
+	return p
+}
@@ -0,0 +1,58 @@
+package sourcemap
+
+import "testing"
+
+func TestColumns(t *testing.T) {
+	sample := []struct {
+		line         string
+		bytes, utf16 int
+	}{
+		{"var x", 4, 4},
+		{`"é" + x`, 7, 6},
+		{`"日本" + x`, 11, 7},
+		{`"😀" + x`, 9, 7},
+		{"é", 5, 4},
+		{"", 3, 3},
+	}
+	for _, v := range sample {
+		if c := utf16Column(v.line, v.bytes); c != v.utf16 {
+			t.Errorf("%q: expected byte column %d to be UTF-16 column %d got %d", v.line, v.bytes, v.utf16, c)
+		}
+		if c := byteColumn(v.line, v.utf16); c != v.bytes {
+			t.Errorf("%q: expected UTF-16 column %d to be byte column %d got %d", v.line, v.utf16, v.bytes, c)
+		}
+	}
+
+	lines := splitLines("a\nb\r\nc\rd e")
+	if len(lines) != 5 || lines[4] != "e" {
+		t.Errorf("expected 5 lines got %q", lines)
+	}
+}
+
+func TestGeneratorColumns(t *testing.T) {
+	src := "let s = \"日本\"; f(s);\n"
+	out := "var s=\"日本\";f(s);"
+	g := NewGenerator("out.js")
+	g.SetGeneratedContent(out)
+	g.SetSourceContent("a.js", src)
+	// f, after the string in both
+	g.AddMapping(Mapping{Generated: Position{0, 15}, Original: Position{0, 18}, Source: "a.js", Name: "f"})
+	m := g.Map()
+	// 15 and 18 bytes are 11 and 14 UTF-16 code units
+	if m.Mappings != "WAAcA" {
+		t.Errorf("expected WAAcA got %s", m.Mappings)
+	}
+
+	c, err := NewConsumer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetGeneratedContent(out)
+	o, ok := c.Original(Position{0, 15})
+	if !ok || o.Original != (Position{0, 18}) || o.Generated != (Position{0, 15}) {
+		t.Errorf("expected f at %v got %v", Position{0, 18}, o)
+	}
+	if l := c.Locate(Position{0, 16}); l != "a.js:1:19" {
+		t.Errorf("expected a.js:1:19 got %s", l)
+	}
+}
@@ -0,0 +1,294 @@
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Parse decodes a JSON source map.
+func Parse(b []byte) (*Map, error) {
+	// A map may be protected against XSSI by a leading )]}' line.
+	if bytes.HasPrefix(b, []byte(")]}'")) {
+		if i := bytes.IndexByte(b, '\n'); i != -1 {
+			b = b[i+1:]
+		}
+	}
+	m := &Map{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", m.Version)
+	}
+	return m, nil
+}
+
+// Consumer answers queries about the original position of generated code.
+type Consumer struct {
+	Map      *Map
+	mappings []Mapping
+}
+
+// NewConsumer decodes the mappings of m.
+func NewConsumer(m *Map) (*Consumer, error) {
+	c := &Consumer{Map: m}
+	if err := c.decode(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetGeneratedContent gives the text of the generated output, which the
+// columns of generated positions are converted back to bytes on. It is
+// called once, before any query.
+func (c *Consumer) SetGeneratedContent(content string) {
+	lines := lineColumns(splitLines(content))
+	for i := range c.mappings {
+		c.mappings[i].Generated = lines.bytes(c.mappings[i].Generated)
+	}
+}
+
+func (c *Consumer) source(i int) string {
+	return c.resolve(c.Map.Sources[i])
+}
+
+// resolve prepends the source root of the map to s.
+func (c *Consumer) resolve(s string) string {
+	if root := c.Map.SourceRoot; root != "" {
+		if !strings.HasSuffix(root, "/") {
+			root += "/"
+		}
+		s = root + s
+	}
+	return s
+}
+
+func (c *Consumer) decode() error {
+	originals := make([]lineColumns, len(c.Map.Sources))
+	for i, content := range c.Map.SourcesContent {
+		if i < len(originals) && content != nil {
+			originals[i] = splitLines(*content)
+		}
+	}
+	var line, col, src, origLine, origCol, name int
+	s := c.Map.Mappings
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ';':
+			line++
+			col = 0
+			i++
+			continue
+		case ',':
+			i++
+			continue
+		}
+		var fields [5]int
+		n := 0
+		for i < len(s) && s[i] != ',' && s[i] != ';' {
+			if n == len(fields) {
+				return fmt.Errorf("sourcemap: too many fields in segment at %d", i)
+			}
+			v, next, err := decodeVLQ(s, i)
+			if err != nil {
+				return err
+			}
+			fields[n] = v
+			n++
+			i = next
+		}
+		col += fields[0]
+		m := Mapping{Generated: Position{Line: line, Column: col}}
+		switch n {
+		case 1:
+		case 4, 5:
+			src += fields[1]
+			origLine += fields[2]
+			origCol += fields[3]
+			if src < 0 || src >= len(c.Map.Sources) {
+				return fmt.Errorf("sourcemap: source index %d out of range", src)
+			}
+			m.Source = c.source(src)
+			m.Original = originals[src].bytes(Position{Line: origLine, Column: origCol})
+			if n == 5 {
+				name += fields[4]
+				if name < 0 || name >= len(c.Map.Names) {
+					return fmt.Errorf("sourcemap: name index %d out of range", name)
+				}
+				m.Name = c.Map.Names[name]
+			}
+		default:
+			return fmt.Errorf("sourcemap: segment with %d fields", n)
+		}
+		c.mappings = append(c.mappings, m)
+	}
+	sort.SliceStable(c.mappings, func(i, j int) bool {
+		return less(c.mappings[i].Generated, c.mappings[j].Generated)
+	})
+	return nil
+}
+
+func less(a, b Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// Mappings returns all decoded mappings ordered by generated position.
+func (c *Consumer) Mappings() []Mapping {
+	return c.mappings
+}
+
+// Original returns the mapping covering the generated position p, that is the
+// closest mapping on the same line starting at or before p. It reports false
+// when there is none or when that mapping has no original source.
+func (c *Consumer) Original(p Position) (Mapping, bool) {
+	i := sort.Search(len(c.mappings), func(i int) bool {
+		return less(p, c.mappings[i].Generated)
+	})
+	if i == 0 {
+		return Mapping{}, false
+	}
+	m := c.mappings[i-1]
+	if m.Generated.Line != p.Line || m.Source == "" {
+		return Mapping{}, false
+	}
+	return m, true
+}
+
+// Locate describes the generated position p as source:line:column for use in
+// diagnostics, with one based line and column numbers. It falls back to
+// the file named by the map when p has no original position.
+func (c *Consumer) Locate(p Position) string {
+	if m, ok := c.Original(p); ok {
+		return fmt.Sprintf("%s:%d:%d", m.Source, m.Original.Line+1, m.Original.Column+1)
+	}
+	return fmt.Sprintf("%s:%d:%d", c.Map.File, p.Line+1, p.Column+1)
+}
+
+// SourceContent returns the embedded text of source.
+func (c *Consumer) SourceContent(source string) (string, bool) {
+	for i := range c.Map.Sources {
+		if c.source(i) == source && i < len(c.Map.SourcesContent) && c.Map.SourcesContent[i] != nil {
+			return *c.Map.SourcesContent[i], true
+		}
+	}
+	return "", false
+}
+
+// Compose chains two source maps. outer maps the final output to its
+// sources, inner maps one of them, file, to the original sources; file
+// defaults to the file named by inner, and is resolved against the source
+// root of outer like its sources are. The result maps the final output
+// straight to the original sources where it went through file. Segments
+// that inner cannot resolve are dropped, those of other sources are kept as
+// they are.
+func Compose(outer, inner *Consumer, file string) *Generator {
+	if file == "" {
+		file = inner.Map.File
+	}
+	resolved := outer.resolve(file)
+	g := NewGenerator(outer.Map.File)
+	for _, m := range outer.mappings {
+		if m.Source == "" {
+			continue
+		}
+		if m.Source != file && m.Source != resolved {
+			g.AddMapping(m)
+			continue
+		}
+		o, ok := inner.Original(m.Original)
+		if !ok {
+			continue
+		}
+		name := o.Name
+		if name == "" {
+			name = m.Name
+		}
+		g.AddMapping(Mapping{
+			Generated: m.Generated,
+			Original:  o.Original,
+			Source:    o.Source,
+			Name:      name,
+		})
+	}
+	for _, s := range g.sources {
+		c, ok := inner.SourceContent(s)
+		if !ok {
+			c, ok = outer.SourceContent(s)
+		}
+		if ok {
+			g.SetSourceContent(s, c)
+		}
+	}
+	return g
+}
+
+var urlMarkers = []string{"//# sourceMappingURL=", "//@ sourceMappingURL="}
+
+// SourceMappingURL returns the url of the last sourceMappingURL comment in
+// src, or an empty string when there is none.
+func SourceMappingURL(src []byte) string {
+	pos, marker := -1, ""
+	for _, m := range urlMarkers {
+		if i := bytes.LastIndex(src, []byte(m)); i > pos {
+			pos, marker = i, m
+		}
+	}
+	if pos == -1 {
+		return ""
+	}
+	rest := src[pos+len(marker):]
+	if i := bytes.IndexAny(rest, "\r\n"); i != -1 {
+		rest = rest[:i]
+	}
+	return strings.TrimSpace(string(rest))
+}
+
+// Load reads the source map referenced by u, either an inline data URL or a
+// path relative to dir.
+func Load(u, dir string) (*Map, error) {
+	if strings.HasPrefix(u, "data:") {
+		i := strings.IndexByte(u, ',')
+		if i == -1 {
+			return nil, errors.New("sourcemap: malformed data url")
+		}
+		meta, data := u[len("data:"):i], u[i+1:]
+		var b []byte
+		if strings.HasSuffix(meta, ";base64") {
+			d, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, err
+			}
+			b = d
+		} else {
+			d, err := url.PathUnescape(data)
+			if err != nil {
+				return nil, err
+			}
+			b = []byte(d)
+		}
+		return Parse(b)
+	}
+	p, err := url.PathUnescape(u)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, filepath.FromSlash(p))
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
@@ -0,0 +1,222 @@
+package sourcemap
+
+import (
+	"testing"
+)
+
+func TestConsumer(t *testing.T) {
+	m, err := Parse([]byte(`)]}'
+{"version":3,"file":"out.js","sourceRoot":"src","sources":["a.js"],"names":["x"],"mappings":"AAAA,IAAIA;AACJ;;E"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewConsumer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []Mapping{
+		{Generated: Position{0, 0}, Original: Position{0, 0}, Source: "src/a.js"},
+		{Generated: Position{0, 4}, Original: Position{0, 4}, Source: "src/a.js", Name: "x"},
+		{Generated: Position{1, 0}, Original: Position{1, 0}, Source: "src/a.js"},
+		{Generated: Position{3, 2}},
+	}
+	got := c.Mappings()
+	if len(got) != len(e) {
+		t.Fatalf("expected %d mappings got %d", len(e), len(got))
+	}
+	for i := range e {
+		if got[i] != e[i] {
+			t.Errorf("expected %v got %v", e[i], got[i])
+		}
+	}
+
+	o, ok := c.Original(Position{0, 6})
+	if !ok {
+		t.Fatal("expected an original position")
+	}
+	if o.Name != "x" || o.Original != (Position{0, 4}) {
+		t.Errorf("expected x at %v got %s at %v", Position{0, 4}, o.Name, o.Original)
+	}
+	if _, ok := c.Original(Position{3, 5}); ok {
+		t.Error("expected no original position for an unmapped segment")
+	}
+	if _, ok := c.Original(Position{2, 0}); ok {
+		t.Error("expected no original position for an empty line")
+	}
+	if l := c.Locate(Position{1, 3}); l != "src/a.js:2:1" {
+		t.Errorf("expected src/a.js:2:1 got %s", l)
+	}
+	if l := c.Locate(Position{2, 0}); l != "out.js:3:1" {
+		t.Errorf("expected out.js:3:1 got %s", l)
+	}
+
+	bad := []string{
+		`{"version":2,"sources":[],"names":[],"mappings":""}`,
+		`{"version":3,"sources":[],"names":[],"mappings":"AAAA"}`,
+		`{"version":3,"sources":["a.js"],"names":[],"mappings":"AA"}`,
+		`{"version":3,"sources":["a.js"],"names":[],"mappings":"AAAAA"}`,
+	}
+	for _, v := range bad {
+		m, err := Parse([]byte(v))
+		if err == nil {
+			_, err = NewConsumer(m)
+		}
+		if err == nil {
+			t.Errorf("expected an error for %s", v)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	// inner: bundle.js -> a.js
+	ig := NewGenerator("bundle.js")
+	ig.AddMapping(Mapping{Generated: Position{5, 2}, Original: Position{0, 0}, Source: "a.js", Name: "foo"})
+	ig.AddMapping(Mapping{Generated: Position{5, 10}, Original: Position{0, 8}, Source: "a.js"})
+	ig.SetSourceContent("a.js", "function foo() {}")
+	inner, err := NewConsumer(ig.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// outer: out.js -> bundle.js
+	og := NewGenerator("out.js")
+	og.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{5, 2}, Source: "bundle.js", Name: "a"})
+	og.AddMapping(Mapping{Generated: Position{0, 4}, Original: Position{5, 12}, Source: "bundle.js"})
+	og.AddMapping(Mapping{Generated: Position{0, 8}, Original: Position{1, 0}, Source: "bundle.js"})
+	outer, err := NewConsumer(og.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewConsumer(Compose(outer, inner, "").Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []Mapping{
+		{Generated: Position{0, 0}, Original: Position{0, 0}, Source: "a.js", Name: "foo"},
+		{Generated: Position{0, 4}, Original: Position{0, 8}, Source: "a.js"},
+	}
+	got := c.Mappings()
+	if len(got) != len(e) {
+		t.Fatalf("expected %d mappings got %d", len(e), len(got))
+	}
+	for i := range e {
+		if got[i] != e[i] {
+			t.Errorf("expected %v got %v", e[i], got[i])
+		}
+	}
+	if s, ok := c.SourceContent("a.js"); !ok || s != "function foo() {}" {
+		t.Errorf("expected the content of a.js to be carried over got %q", s)
+	}
+}
+
+func TestComposeOtherSources(t *testing.T) {
+	// inner: bundle.js -> orig.js
+	ig := NewGenerator("bundle.js")
+	ig.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{7, 3}, Source: "orig.js"})
+	inner, err := NewConsumer(ig.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// outer: out.js -> bundle.js and other.js
+	og := NewGenerator("out.js")
+	og.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{0, 0}, Source: "bundle.js"})
+	og.AddMapping(Mapping{Generated: Position{1, 0}, Original: Position{0, 0}, Source: "other.js", Name: "x"})
+	og.SetSourceContent("other.js", "var x;")
+	outer, err := NewConsumer(og.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"", "bundle.js"} {
+		c, err := NewConsumer(Compose(outer, inner, file).Map())
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := []Mapping{
+			{Generated: Position{0, 0}, Original: Position{7, 3}, Source: "orig.js"},
+			{Generated: Position{1, 0}, Original: Position{0, 0}, Source: "other.js", Name: "x"},
+		}
+		got := c.Mappings()
+		if len(got) != len(e) {
+			t.Fatalf("%q: expected %d mappings got %d", file, len(e), len(got))
+		}
+		for i := range e {
+			if got[i] != e[i] {
+				t.Errorf("%q: expected %v got %v", file, e[i], got[i])
+			}
+		}
+		if s, ok := c.SourceContent("other.js"); !ok || s != "var x;" {
+			t.Errorf("%q: expected the content of other.js to be kept got %q", file, s)
+		}
+	}
+}
+
+func TestComposeSourceRoot(t *testing.T) {
+	// inner: bundle.js -> orig.js
+	ig := NewGenerator("bundle.js")
+	ig.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{7, 3}, Source: "orig.js"})
+	inner, err := NewConsumer(ig.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// outer: out.js -> /build/bundle.js and /build/other.js
+	og := NewGenerator("out.js")
+	og.SourceRoot = "/build/"
+	og.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{0, 0}, Source: "bundle.js"})
+	og.AddMapping(Mapping{Generated: Position{1, 0}, Original: Position{0, 0}, Source: "other.js"})
+	outer, err := NewConsumer(og.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"", "bundle.js", "/build/bundle.js"} {
+		c, err := NewConsumer(Compose(outer, inner, file).Map())
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := []Mapping{
+			{Generated: Position{0, 0}, Original: Position{7, 3}, Source: "orig.js"},
+			{Generated: Position{1, 0}, Original: Position{0, 0}, Source: "/build/other.js"},
+		}
+		got := c.Mappings()
+		if len(got) != len(e) {
+			t.Fatalf("%q: expected %d mappings got %d", file, len(e), len(got))
+		}
+		for i := range e {
+			if got[i] != e[i] {
+				t.Errorf("%q: expected %v got %v", file, e[i], got[i])
+			}
+		}
+	}
+}
+
+func TestSourceMappingURL(t *testing.T) {
+	sample := map[string]string{
+		"var a;\n//# sourceMappingURL=a.js.map\n":                    "a.js.map",
+		"var a;\n//@ sourceMappingURL=old.map":                       "old.map",
+		"//# sourceMappingURL=x.map\n//# sourceMappingURL=y.map\r\n": "y.map",
+		"var a;": "",
+	}
+	for src, e := range sample {
+		if u := SourceMappingURL([]byte(src)); u != e {
+			t.Errorf("expected %q got %q", e, u)
+		}
+	}
+
+	g := NewGenerator("out.js")
+	g.AddMapping(Mapping{Generated: Position{0, 0}, Original: Position{2, 1}, Source: "a.js"})
+	comment, err := g.InlineComment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(SourceMappingURL([]byte("a();\n"+comment)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.File != "out.js" || m.Mappings != g.Map().Mappings {
+		t.Errorf("expected the inline map to round trip got %v", m)
+	}
+}
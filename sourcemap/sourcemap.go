@@ -0,0 +1,194 @@
+// Package sourcemap implements Source Map revision 3.
+//
+// https://sourcemaps.info/spec.html
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+)
+
+const inlinePrefix = "//# sourceMappingURL=data:application/json;charset=utf-8;base64,"
+
+// Position is a zero based line and column. Columns are counted in bytes,
+// the same way the lexer counts them.
+//
+// Source maps count columns in UTF-16 code units instead. Generator and
+// Consumer convert between the two on the lines whose text they know: the
+// generated output given to SetGeneratedContent and the original sources in
+// sourcesContent. Other columns are taken to be ASCII, where both agree.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Mapping links a position in the generated output to a position in one of
+// the original sources. A mapping with an empty Source only marks a
+// generated position that has no original.
+type Mapping struct {
+	Generated Position
+	Original  Position
+	Source    string
+	Name      string
+}
+
+// Map is the JSON representation of a source map.
+type Map struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+}
+
+// Generator collects mappings while output is being written and encodes them
+// into a Map.
+type Generator struct {
+	// SourceRoot is prepended to the sources by consumers of the map.
+	SourceRoot string
+
+	file      string
+	generated lineColumns
+	sources   []string
+	sourceIdx map[string]int
+	contents  map[string]string
+	names     []string
+	nameIdx   map[string]int
+	mappings  []Mapping
+}
+
+// NewGenerator returns a Generator for the generated file named file.
+func NewGenerator(file string) *Generator {
+	return &Generator{
+		file:      file,
+		sourceIdx: make(map[string]int),
+		contents:  make(map[string]string),
+		nameIdx:   make(map[string]int),
+	}
+}
+
+// AddMapping records m.
+func (g *Generator) AddMapping(m Mapping) {
+	if m.Source != "" {
+		g.source(m.Source)
+		if m.Name != "" {
+			g.name(m.Name)
+		}
+	}
+	g.mappings = append(g.mappings, m)
+}
+
+// SetSourceContent embeds content as the text of source in sourcesContent.
+func (g *Generator) SetSourceContent(source, content string) {
+	g.source(source)
+	g.contents[source] = content
+}
+
+// SetGeneratedContent gives the text of the generated output, which the
+// columns of generated positions are converted on.
+func (g *Generator) SetGeneratedContent(content string) {
+	g.generated = splitLines(content)
+}
+
+func (g *Generator) source(s string) int {
+	if i, ok := g.sourceIdx[s]; ok {
+		return i
+	}
+	i := len(g.sources)
+	g.sources = append(g.sources, s)
+	g.sourceIdx[s] = i
+	return i
+}
+
+func (g *Generator) name(s string) int {
+	if i, ok := g.nameIdx[s]; ok {
+		return i
+	}
+	i := len(g.names)
+	g.names = append(g.names, s)
+	g.nameIdx[s] = i
+	return i
+}
+
+// Map encodes the collected mappings.
+func (g *Generator) Map() *Map {
+	m := &Map{
+		Version:    3,
+		File:       g.file,
+		SourceRoot: g.SourceRoot,
+		Sources:    append([]string{}, g.sources...),
+		Names:      append([]string{}, g.names...),
+		Mappings:   g.encodeMappings(),
+	}
+	if len(g.contents) > 0 {
+		m.SourcesContent = make([]*string, len(g.sources))
+		for i, s := range g.sources {
+			if c, ok := g.contents[s]; ok {
+				m.SourcesContent[i] = &c
+			}
+		}
+	}
+	return m
+}
+
+func (g *Generator) encodeMappings() string {
+	mappings := append([]Mapping{}, g.mappings...)
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return less(mappings[i].Generated, mappings[j].Generated)
+	})
+	originals := make(map[string]lineColumns)
+	for s, c := range g.contents {
+		originals[s] = splitLines(c)
+	}
+	var b bytes.Buffer
+	var line, col, src, origLine, origCol, name int
+	for i, m := range mappings {
+		m.Generated = g.generated.utf16(m.Generated)
+		m.Original = originals[m.Source].utf16(m.Original)
+		if m.Generated.Line != line {
+			for ; line < m.Generated.Line; line++ {
+				b.WriteByte(';')
+			}
+			col = 0
+		} else if i > 0 {
+			b.WriteByte(',')
+		}
+		encodeVLQ(&b, m.Generated.Column-col)
+		col = m.Generated.Column
+		if m.Source == "" {
+			continue
+		}
+		s := g.sourceIdx[m.Source]
+		encodeVLQ(&b, s-src)
+		src = s
+		encodeVLQ(&b, m.Original.Line-origLine)
+		origLine = m.Original.Line
+		encodeVLQ(&b, m.Original.Column-origCol)
+		origCol = m.Original.Column
+		if m.Name != "" {
+			n := g.nameIdx[m.Name]
+			encodeVLQ(&b, n-name)
+			name = n
+		}
+	}
+	return b.String()
+}
+
+// MarshalJSON returns the encoded source map.
+func (g *Generator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.Map())
+}
+
+// InlineComment returns a sourceMappingURL comment carrying the source map
+// as a base64 data URL, suitable for appending to the generated output.
+func (g *Generator) InlineComment() (string, error) {
+	b, err := g.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	return inlinePrefix + base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,55 @@
+package sourcemap
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator("out.js")
+	g.AddMapping(Mapping{
+		Generated: Position{Line: 1, Column: 0},
+		Original:  Position{Line: 1, Column: 0},
+		Source:    "a.js",
+	})
+	g.AddMapping(Mapping{
+		Generated: Position{Line: 0, Column: 4},
+		Original:  Position{Line: 0, Column: 4},
+		Source:    "a.js",
+		Name:      "x",
+	})
+	g.AddMapping(Mapping{
+		Generated: Position{Line: 0, Column: 0},
+		Original:  Position{Line: 0, Column: 0},
+		Source:    "a.js",
+	})
+	g.AddMapping(Mapping{
+		Generated: Position{Line: 3, Column: 2},
+	})
+	g.SetSourceContent("a.js", "var x = 1;\nx++;")
+
+	b, err := g.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `{"version":3,"file":"out.js","sources":["a.js"],"sourcesContent":["var x = 1;\nx++;"],"names":["x"],"mappings":"AAAA,IAAIA;AACJ;;E"}`
+	if string(b) != e {
+		t.Errorf("expected %s got %s", e, string(b))
+	}
+
+	c, err := g.InlineComment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(c, inlinePrefix) {
+		t.Fatalf("expected %s to start with %s", c, inlinePrefix)
+	}
+	d, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(c, inlinePrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != e {
+		t.Errorf("expected %s got %s", e, string(d))
+	}
+}
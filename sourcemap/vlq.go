@@ -0,0 +1,75 @@
+package sourcemap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+const (
+	vlqShift        = 5
+	vlqBase         = 1 << vlqShift
+	vlqMask         = vlqBase - 1
+	vlqContinuation = vlqBase
+)
+
+// encodeVLQ writes n as a base64 VLQ. The sign is stored in the least
+// significant bit of the first digit.
+func encodeVLQ(b *bytes.Buffer, n int) {
+	v := n << 1
+	if n < 0 {
+		v = (-n << 1) | 1
+	}
+	for {
+		digit := v & vlqMask
+		v >>= vlqShift
+		if v > 0 {
+			digit |= vlqContinuation
+		}
+		b.WriteByte(base64Chars[digit])
+		if v == 0 {
+			return
+		}
+	}
+}
+
+var base64Index [256]int
+
+func init() {
+	for i := range base64Index {
+		base64Index[i] = -1
+	}
+	for i := 0; i < len(base64Chars); i++ {
+		base64Index[base64Chars[i]] = i
+	}
+}
+
+// decodeVLQ reads one base64 VLQ from s starting at offset i. It returns the
+// decoded value and the offset just past it.
+func decodeVLQ(s string, i int) (int, int, error) {
+	var v, shift int
+	for {
+		if i >= len(s) {
+			return 0, i, errors.New("sourcemap: unexpected end of VLQ")
+		}
+		digit := base64Index[s[i]]
+		if digit == -1 {
+			return 0, i, fmt.Errorf("sourcemap: invalid base64 character %q at %d", s[i], i)
+		}
+		i++
+		v += (digit & vlqMask) << shift
+		if digit&vlqContinuation == 0 {
+			break
+		}
+		shift += vlqShift
+		if shift > 30 {
+			return 0, i, fmt.Errorf("sourcemap: VLQ overflow at %d", i)
+		}
+	}
+	if v&1 == 1 {
+		return -(v >> 1), i, nil
+	}
+	return v >> 1, i, nil
+}
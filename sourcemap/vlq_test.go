@@ -0,0 +1,49 @@
+package sourcemap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeVLQ(t *testing.T) {
+	sample := map[int]string{
+		0:     "A",
+		1:     "C",
+		-1:    "D",
+		15:    "e",
+		16:    "gB",
+		-16:   "hB",
+		123:   "2H",
+		98765: "68gG",
+	}
+	for n, e := range sample {
+		var b bytes.Buffer
+		encodeVLQ(&b, n)
+		if b.String() != e {
+			t.Errorf("%d: expected %s got %s", n, e, b.String())
+		}
+	}
+}
+
+func TestDecodeVLQ(t *testing.T) {
+	for _, n := range []int{0, 1, -1, 15, 16, -16, 123, 98765, -98765} {
+		var b bytes.Buffer
+		encodeVLQ(&b, n)
+		v, i, err := decodeVLQ(b.String(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != n {
+			t.Errorf("expected %d got %d", n, v)
+		}
+		if i != b.Len() {
+			t.Errorf("%d: expected to consume %d got %d", n, b.Len(), i)
+		}
+	}
+	bad := []string{"", "g", "!", "gggggggggA"}
+	for _, v := range bad {
+		if _, _, err := decodeVLQ(v, 0); err == nil {
+			t.Errorf("expected an error for %q", v)
+		}
+	}
+}